@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// config holds the parsed command line.
+type config struct {
+	method  string
+	url     string
+	headers http.Header
+	body    []byte
+	timeout time.Duration
+	retries int
+	user    string
+	bearer  string
+	verbose bool
+}
+
+// headerFlags collects repeated -H flags.
+type headerFlags http.Header
+
+func (headers headerFlags) String() string {
+	return ""
+}
+
+func (headers headerFlags) Set(value string) error {
+	key, headerValue, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("header %q is not in the form 'Name: value'", value)
+	}
+	http.Header(headers).Add(strings.TrimSpace(key), strings.TrimSpace(headerValue))
+	return nil
+}
+
+func parseFlags(args []string, stdin io.Reader, stderr io.Writer) (*config, error) {
+	cfg := &config{headers: http.Header{}}
+	flags := flag.NewFlagSet("httpc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: httpc [flags] URL")
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&cfg.method, "X", "", "request method, defaults to GET or to POST with a body")
+	flags.Var(headerFlags(cfg.headers), "H", "request header 'Name: value', repeatable")
+	bodyPath := flags.String("body", "", "JSON request body file, - reads stdin")
+	flags.DurationVar(&cfg.timeout, "timeout", 30*time.Second, "timeout of the whole request, 0 disables it")
+	flags.IntVar(&cfg.retries, "retries", 0, "retries of failed requests, POST and PATCH get an Idempotency-Key")
+	flags.StringVar(&cfg.user, "user", "", "basic auth credentials user:password")
+	flags.StringVar(&cfg.bearer, "bearer", "", "bearer token")
+	flags.BoolVar(&cfg.verbose, "v", false, "print request and response headers and a timing breakdown to stderr")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return nil, fmt.Errorf("expected exactly one URL, got %d arguments", flags.NArg())
+	}
+	cfg.url = flags.Arg(0)
+	if cfg.retries < 0 {
+		return nil, fmt.Errorf("-retries must not be negative")
+	}
+	if cfg.user != "" && cfg.bearer != "" {
+		return nil, fmt.Errorf("-user and -bearer are mutually exclusive")
+	}
+	if cfg.user != "" && !strings.Contains(cfg.user, ":") {
+		return nil, fmt.Errorf("-user must be in the form user:password")
+	}
+
+	if *bodyPath != "" {
+		body, err := readBody(*bodyPath, stdin)
+		if err != nil {
+			return nil, err
+		}
+		cfg.body = body
+	}
+	if cfg.method == "" {
+		cfg.method = http.MethodGet
+		if cfg.body != nil {
+			cfg.method = http.MethodPost
+		}
+	}
+	cfg.method = strings.ToUpper(cfg.method)
+	return cfg, nil
+}
+
+func readBody(path string, stdin io.Reader) ([]byte, error) {
+	var body []byte
+	var err error
+	if path == "-" {
+		body, err = io.ReadAll(stdin)
+	} else {
+		body, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading request body: %w", err)
+	}
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("request body %s is not valid JSON", path)
+	}
+	return body, nil
+}
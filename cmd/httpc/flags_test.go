@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlags(t *testing.T) {
+	bodyPath := filepath.Join(t.TempDir(), "user.json")
+	assert.NoError(t, os.WriteFile(bodyPath, []byte(`{"name":"ada"}`), 0o644))
+
+	cfg, err := parseFlags([]string{
+		"-H", "Accept: application/json",
+		"-H", "X-Tag: one",
+		"-H", "X-Tag:two",
+		"-body", bodyPath,
+		"-timeout", "2s",
+		"http://rain.us/users",
+	}, strings.NewReader(""), io.Discard)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodPost, cfg.method)
+	assert.Equal(t, "http://rain.us/users", cfg.url)
+	assert.Equal(t, []string{"one", "two"}, cfg.headers.Values("X-Tag"))
+	assert.Equal(t, "application/json", cfg.headers.Get("Accept"))
+	assert.Equal(t, `{"name":"ada"}`, string(cfg.body))
+	assert.Equal(t, 2*time.Second, cfg.timeout)
+}
+
+func TestParseFlags_Method(t *testing.T) {
+	cfg, err := parseFlags([]string{"http://rain.us"}, strings.NewReader(""), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodGet, cfg.method)
+
+	cfg, err = parseFlags([]string{"-X", "delete", "http://rain.us"}, strings.NewReader(""), io.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodDelete, cfg.method)
+}
+
+func TestParseFlags_MissingBodyFile(t *testing.T) {
+	_, err := parseFlags([]string{"-body", filepath.Join(t.TempDir(), "missing.json"), "http://rain.us"}, strings.NewReader(""), io.Discard)
+
+	assert.ErrorContains(t, err, "reading request body")
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httperror"
+	"github.com/ttanik/http-client/httprequester"
+	"github.com/ttanik/http-client/httptiming"
+)
+
+const (
+	exitOK = iota
+	_
+	exitUsage
+	exitRequestFailed
+	exitTimeout
+	exitClientError
+	exitServerError
+)
+
+// errorOutput is an HTTPError as printed on stderr. Unlike HTTPError it
+// keeps the wrapped error.
+type errorOutput struct {
+	Status  int                `json:"status"`
+	Message string             `json:"message"`
+	Error   string             `json:"error,omitempty"`
+	Time    time.Time          `json:"time"`
+	Timing  *httptiming.Timing `json:"timing,omitempty"`
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	cfg, err := parseFlags(args, stdin, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		writeError(stderr, &httperror.HTTPError{Status: http.StatusBadRequest, Message: "invalid arguments", Err: err})
+		return exitUsage
+	}
+
+	decoder := httpdecoder.NewHTTPDecoder()
+	requester := httprequester.NewHTTPRequester(&http.Client{}, decoder)
+	if cfg.retries > 0 {
+		requester.WithRetry(httprequester.RetryPolicy{MaxAttempts: cfg.retries + 1})
+	}
+	options := []httpclient.Option{
+		httpclient.WithRequester(requester),
+		httpclient.WithTimeout(cfg.timeout),
+		httpclient.WithAcceptEncoding(decoder.AcceptEncoding()),
+	}
+	if cfg.retries > 0 {
+		options = append(options, httpclient.WithIdempotencyKeys(httpclient.UUIDv4))
+	}
+	if cfg.verbose {
+		options = append(options, httpclient.WithTiming())
+	}
+	client := httpclient.NewHTTPClient(options...)
+
+	request, httpError := newRequestBuilder(context.Background(), client, cfg).Build()
+	if httpError != nil {
+		writeError(stderr, httpError)
+		return exitUsage
+	}
+	if cfg.verbose {
+		fmt.Fprintf(stderr, "> %s %s\n", request.Method, request.URL)
+		writeHeaders(stderr, ">", request.Header)
+	}
+
+	start := time.Now()
+	response, httpError := client.ExecuteRequest(request)
+	if httpError != nil {
+		if cfg.verbose && httpError.Timing != nil {
+			writeTiming(stderr, *httpError.Timing, time.Since(start))
+		}
+		writeError(stderr, httpError)
+		return exitCode(httpError)
+	}
+
+	if cfg.verbose {
+		fmt.Fprintf(stderr, "< %s %s\n", response.Proto, response.Status)
+		writeHeaders(stderr, "<", response.Header)
+	}
+	fmt.Fprintf(stdout, "%s %s\n", response.Proto, response.Status)
+	httpError = writeBody(decoder, response, stdout)
+	if timing, ok := httptiming.FromResponse(response); ok && cfg.verbose {
+		writeTiming(stderr, timing, time.Since(start))
+	}
+	if httpError != nil {
+		writeError(stderr, httpError)
+		return exitCode(httpError)
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		writeError(stderr, &httperror.HTTPError{Status: response.StatusCode, Message: "unexpected response status", Time: time.Now()})
+		return statusExitCode(response.StatusCode)
+	}
+	return exitOK
+}
+
+func newRequestBuilder(ctx context.Context, client *httpclient.Client, cfg *config) httpclient.HTTPRequestBuilder {
+	builder := client.NewRequestBuilder(ctx).
+		WithMethod(cfg.method).
+		WithEndpoint(cfg.url)
+	if cfg.body != nil {
+		builder = builder.
+			WithBody(cfg.body).
+			WithHeader("Content-Type", "application/json")
+	}
+
+	switch {
+	case cfg.user != "":
+		builder = builder.WithHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(cfg.user)))
+	case cfg.bearer != "":
+		builder = builder.WithHeader("Authorization", "Bearer "+cfg.bearer)
+	}
+
+	for key, values := range cfg.headers {
+		builder = builder.WithoutHeader(key)
+		for _, value := range values {
+			builder = builder.AddHeader(key, value)
+		}
+	}
+	return builder
+}
+
+// writeBody prints the body of response, indented when it is JSON.
+func writeBody(decoder *httpdecoder.Decoder, response *http.Response, stdout io.Writer) *httperror.HTTPError {
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if httpError := decoder.Decompress(response); httpError != nil {
+		return httpError
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		status, message := http.StatusInternalServerError, "error reading response body"
+		if errors.Is(err, context.DeadlineExceeded) {
+			status, message = http.StatusGatewayTimeout, "request timed out"
+		}
+		return &httperror.HTTPError{Status: status, Message: message, Err: err, Time: time.Now()}
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	var indented bytes.Buffer
+	if json.Valid(body) && json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	_, _ = stdout.Write(body)
+	if body[len(body)-1] != '\n' {
+		fmt.Fprintln(stdout)
+	}
+	return nil
+}
+
+func writeHeaders(writer io.Writer, prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range header.Values(key) {
+			if key == "Authorization" {
+				value = "[redacted]"
+			}
+			fmt.Fprintf(writer, "%s %s: %s\n", prefix, key, value)
+		}
+	}
+}
+
+// writeTiming prints the phases of the last attempt. total includes reading
+// the body.
+func writeTiming(writer io.Writer, timing httptiming.Timing, total time.Duration) {
+	fmt.Fprintf(writer, "* attempts:    %d\n", timing.Attempts)
+	if timing.ConnReused {
+		fmt.Fprintf(writer, "* connection:  reused after %s idle\n", timing.ConnIdle)
+	} else {
+		fmt.Fprintf(writer, "* dns:         %s\n", timing.DNSLookup)
+		fmt.Fprintf(writer, "* connect:     %s\n", timing.Connect)
+		fmt.Fprintf(writer, "* tls:         %s\n", timing.TLSHandshake)
+	}
+	fmt.Fprintf(writer, "* server:      %s\n", timing.ServerProcessing)
+	fmt.Fprintf(writer, "* first byte:  %s\n", timing.TimeToFirstByte)
+	fmt.Fprintf(writer, "* total:       %s\n", total)
+}
+
+func writeError(stderr io.Writer, httpError *httperror.HTTPError) {
+	output := errorOutput{Status: httpError.Status, Message: httpError.Message, Time: httpError.Time, Timing: httpError.Timing}
+	if httpError.Err != nil {
+		output.Error = httpError.Err.Error()
+	}
+	if output.Time.IsZero() {
+		output.Time = time.Now()
+	}
+
+	encoder := json.NewEncoder(stderr)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(output)
+}
+
+// exitCode maps errors of the requester stack to the exit codes documented
+// in the package comment.
+func exitCode(httpError *httperror.HTTPError) int {
+	switch httpError.Status {
+	case http.StatusGatewayTimeout:
+		return exitTimeout
+	case http.StatusFailedDependency:
+		return exitServerError
+	}
+	return exitRequestFailed
+}
+
+func statusExitCode(status int) int {
+	if status >= http.StatusInternalServerError {
+		return exitServerError
+	}
+	return exitClientError
+}
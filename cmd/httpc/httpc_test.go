@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func runCommand(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func decodeError(t *testing.T, stderr string) errorOutput {
+	var output errorOutput
+	// Verbose output comes first.
+	stderr = stderr[strings.Index(stderr, "{\n"):]
+	assert.NoError(t, json.NewDecoder(strings.NewReader(stderr)).Decode(&output))
+	return output
+}
+
+func TestRun_PrettyPrintsJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodGet, request.Method)
+		assert.Equal(t, "acme", request.Header.Get("X-Tenant"))
+		assert.Equal(t, "Bearer secret", request.Header.Get("Authorization"))
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"id":42,"tags":["a"]}`))
+	}))
+	defer server.Close()
+
+	code, stdout, stderr := runCommand("", "-H", "X-Tenant: acme", "-bearer", "secret", server.URL+"/users/42")
+
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stderr)
+	assert.Equal(t, "HTTP/1.1 200 OK\n{\n  \"id\": 42,\n  \"tags\": [\n    \"a\"\n  ]\n}\n", stdout)
+}
+
+func TestRun_BodyFromStdin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+		assert.JSONEq(t, `{"name":"ada"}`, string(body))
+		user, password, _ := request.BasicAuth()
+		assert.Equal(t, "ada:pw", user+":"+password)
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write([]byte("created"))
+	}))
+	defer server.Close()
+
+	code, stdout, _ := runCommand(`{"name":"ada"}`, "-body", "-", "-user", "ada:pw", server.URL)
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "HTTP/1.1 201 Created\ncreated\n", stdout)
+}
+
+func TestRun_StatusExitCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/missing":
+			writer.WriteHeader(http.StatusNotFound)
+			_, _ = writer.Write([]byte(`{"message":"no such user"}`))
+		case "/broken":
+			writer.WriteHeader(http.StatusInternalServerError)
+			_, _ = writer.Write([]byte(`{"message":"boom"}`))
+		case "/unavailable":
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	code, stdout, stderr := runCommand("", server.URL+"/missing")
+	assert.Equal(t, exitClientError, code)
+	assert.Contains(t, stdout, "no such user")
+	assert.Equal(t, http.StatusNotFound, decodeError(t, stderr).Status)
+
+	code, _, stderr = runCommand("", "-v", server.URL+"/broken")
+	assert.Equal(t, exitServerError, code)
+	output := decodeError(t, stderr)
+	assert.Equal(t, http.StatusFailedDependency, output.Status)
+	assert.Equal(t, "dependency failed", output.Message)
+	assert.Contains(t, output.Error, "boom")
+	assert.Equal(t, 1, output.Timing.Attempts)
+
+	code, _, _ = runCommand("", server.URL+"/unavailable")
+	assert.Equal(t, exitServerError, code)
+}
+
+func TestRun_Retries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.NotEmpty(t, request.Header.Get("Idempotency-Key"))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = writer.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	code, _, stderr := runCommand(`{}`, "-body", "-", "-retries", "1", "-v", server.URL)
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Contains(t, stderr, "* attempts:    2")
+}
+
+func TestRun_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-request.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	code, _, stderr := runCommand("", "-timeout", "20ms", server.URL)
+
+	assert.Equal(t, exitTimeout, code)
+	assert.Equal(t, "request timed out", decodeError(t, stderr).Message)
+}
+
+func TestRun_ConnectionFailure(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	code, stdout, stderr := runCommand("", server.URL)
+
+	assert.Equal(t, exitRequestFailed, code)
+	assert.Empty(t, stdout)
+	output := decodeError(t, stderr)
+	assert.Equal(t, http.StatusInternalServerError, output.Status)
+	assert.Equal(t, "error executing request", output.Message)
+	assert.NotEmpty(t, output.Error)
+}
+
+func TestRun_Verbose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Served-By", "test")
+	}))
+	defer server.Close()
+
+	code, _, stderr := runCommand("", "-v", "-bearer", "secret", server.URL)
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "> GET "+server.URL)
+	assert.Contains(t, stderr, "> Authorization: [redacted]")
+	assert.NotContains(t, stderr, "secret")
+	assert.Contains(t, stderr, "< HTTP/1.1 200 OK")
+	assert.Contains(t, stderr, "< X-Served-By: test")
+	assert.Contains(t, stderr, "* first byte:")
+	assert.Contains(t, stderr, "* total:")
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	tests := map[string][]string{
+		"expected exactly one URL, got 0 arguments":          {},
+		"-user and -bearer are mutually exclusive":           {"-user", "a:b", "-bearer", "c", "http://rain.us"},
+		"-user must be in the form user:password":            {"-user", "ada", "http://rain.us"},
+		"request body - is not valid JSON":                   {"-body", "-", "http://rain.us"},
+		"-retries must not be negative":                      {"-retries", "-1", "http://rain.us"},
+		`header "X-Tenant" is not in the form 'Name: value'`: {"-H", "X-Tenant", "http://rain.us"},
+	}
+
+	for message, args := range tests {
+		code, _, stderr := runCommand("{", args...)
+
+		assert.Equal(t, exitUsage, code, message)
+		assert.Contains(t, stderr, message)
+	}
+}
@@ -0,0 +1,26 @@
+// Command httpc sends a single request through the same RequestBuilder,
+// HTTPRequester and Decoder stack as our services, which makes it handy to
+// reproduce production issues:
+//
+//	httpc -H 'X-Tenant: acme' -bearer "$TOKEN" -retries 2 https://api.example.com/users/42
+//	httpc -X PUT -body user.json https://api.example.com/users/42
+//	echo '{"name":"ada"}' | httpc -body - -v https://api.example.com/users
+//
+// The status line goes to stdout, followed by the response body, indented
+// when it is JSON. Failures are written to stderr as HTTPError JSON and exit
+// with:
+//
+//	2  invalid flags or request body
+//	3  the request failed before a response arrived
+//	4  the request timed out
+//	5  the server answered with a 4xx status
+//	6  the server answered with a 5xx status, or the dependency failed
+package main
+
+import (
+	"os"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,713 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+var methods = []struct {
+	name string
+	get  func(item *pathItem) *operation
+}{
+	{http.MethodGet, func(item *pathItem) *operation { return item.Get }},
+	{http.MethodPost, func(item *pathItem) *operation { return item.Post }},
+	{http.MethodPut, func(item *pathItem) *operation { return item.Put }},
+	{http.MethodPatch, func(item *pathItem) *operation { return item.Patch }},
+	{http.MethodDelete, func(item *pathItem) *operation { return item.Delete }},
+}
+
+var methodConstants = map[string]string{
+	http.MethodGet:    "http.MethodGet",
+	http.MethodPost:   "http.MethodPost",
+	http.MethodPut:    "http.MethodPut",
+	http.MethodPatch:  "http.MethodPatch",
+	http.MethodDelete: "http.MethodDelete",
+}
+
+type generator struct {
+	doc *document
+
+	types     map[string]string
+	typeNames []string
+	// underlying holds the underlying type of named non-struct types.
+	underlying map[string]string
+}
+
+// generate renders the client for doc as a formatted Go file.
+func generate(doc *document, packageName string) ([]byte, error) {
+	gen := &generator{doc: doc, types: map[string]string{}, underlying: map[string]string{}}
+
+	schemaNames := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		schemaNames = append(schemaNames, name)
+	}
+	sort.Strings(schemaNames)
+	for _, name := range schemaNames {
+		if err := gen.declare(exportedName(name), doc.Components.Schemas[name]); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	var operations bytes.Buffer
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		for _, method := range methods {
+			op := method.get(item)
+			if op == nil {
+				continue
+			}
+			if err := gen.operation(&operations, method.name, path, item, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method.name, path, err)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by httpclient-gen. DO NOT EDIT.\n\n")
+	if doc.Info.Title != "" {
+		fmt.Fprintf(&out, "// Package %s is a client for %s %s.\n", packageName, doc.Info.Title, doc.Info.Version)
+	}
+	fmt.Fprintf(&out, "package %s\n\n", packageName)
+	out.WriteString(header)
+	if len(doc.Servers) > 0 {
+		fmt.Fprintf(&out, "\n// ServerURL is the first server of the spec, for httpclient.WithBaseURL.\nconst ServerURL = %q\n", doc.Servers[0].URL)
+	}
+	for _, name := range gen.typeNames {
+		out.WriteString("\n" + gen.types[name])
+	}
+	out.Write(operations.Bytes())
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, out.Bytes())
+	}
+	return formatted, nil
+}
+
+// declare adds a named type for an object schema, or an alias-like named
+// type for anything else.
+func (gen *generator) declare(name string, s *schema) error {
+	if _, ok := gen.types[name]; ok {
+		return nil
+	}
+	gen.types[name] = ""
+	gen.typeNames = append(gen.typeNames, name)
+	sort.Strings(gen.typeNames)
+
+	var declaration strings.Builder
+	writeComment(&declaration, name, s.Description)
+
+	if isStruct(s) {
+		fields, err := gen.fields(name, s)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&declaration, "type %s struct {\n%s}\n", name, fields)
+	} else {
+		goType, err := gen.goType(name, s)
+		if err != nil {
+			return err
+		}
+		gen.underlying[name] = goType
+		fmt.Fprintf(&declaration, "type %s %s\n", name, goType)
+	}
+
+	gen.types[name] = declaration.String()
+	return nil
+}
+
+func (gen *generator) fields(typeName string, s *schema) (string, error) {
+	var builder strings.Builder
+	for _, part := range s.AllOf {
+		if part.Ref != "" {
+			goType, err := gen.goType(typeName, part)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&builder, "%s\n", goType)
+			continue
+		}
+		fields, err := gen.fields(typeName, part)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(fields)
+	}
+
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property := s.Properties[name]
+		goType, err := gen.goType(typeName+exportedName(name), property)
+		if err != nil {
+			return "", fmt.Errorf("property %s: %w", name, err)
+		}
+
+		tag := name
+		if !required[name] {
+			tag += ",omitempty"
+			if !gen.isReference(goType) {
+				goType = "*" + goType
+			}
+		} else if property.Nullable && !gen.isReference(goType) {
+			goType = "*" + goType
+		}
+		if property.Description != "" {
+			fmt.Fprintf(&builder, "// %s\n", singleLine(property.Description))
+		}
+		fmt.Fprintf(&builder, "%s %s `json:%q`\n", exportedName(name), goType, tag)
+	}
+	return builder.String(), nil
+}
+
+// isStruct reports whether s becomes a Go struct. Objects with only
+// additionalProperties become maps.
+func isStruct(s *schema) bool {
+	if s.Ref != "" {
+		return false
+	}
+	if len(s.Properties) > 0 || len(s.AllOf) > 0 {
+		return true
+	}
+	return s.Type == "object" && s.AdditionalProperties == nil
+}
+
+// isReference reports whether nil already means absent for the type.
+func (gen *generator) isReference(goType string) bool {
+	if underlying, ok := gen.underlying[goType]; ok {
+		goType = underlying
+	}
+	return strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || goType == "interface{}"
+}
+
+// goType returns the Go type of s, declaring inline objects under hint.
+func (gen *generator) goType(hint string, s *schema) (string, error) {
+	if s == nil {
+		return "interface{}", nil
+	}
+	if s.Ref != "" {
+		name, err := refName(s.Ref, "schemas")
+		if err != nil {
+			return "", err
+		}
+		target, ok := gen.doc.Components.Schemas[name]
+		if !ok {
+			return "", fmt.Errorf("unknown schema %q", s.Ref)
+		}
+		if err := gen.declare(exportedName(name), target); err != nil {
+			return "", err
+		}
+		return exportedName(name), nil
+	}
+
+	switch s.Type {
+	case "string":
+		if s.Format == "binary" {
+			return "[]byte", nil
+		}
+		return "string", nil
+	case "integer":
+		switch s.Format {
+		case "int32":
+			return "int32", nil
+		case "int64":
+			return "int64", nil
+		}
+		return "int", nil
+	case "number":
+		if s.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		items, err := gen.goType(hint+"Item", s.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + items, nil
+	}
+
+	if isStruct(s) && (len(s.Properties) > 0 || len(s.AllOf) > 0) {
+		if err := gen.declare(hint, s); err != nil {
+			return "", err
+		}
+		return hint, nil
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.allowed {
+		values, err := gen.goType(hint+"Value", s.AdditionalProperties.schema)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + values, nil
+	}
+	if s.Type == "object" {
+		return "map[string]interface{}", nil
+	}
+	return "interface{}", nil
+}
+
+type param struct {
+	name     string
+	goName   string
+	goType   string
+	in       string
+	required bool
+	array    bool
+}
+
+func (gen *generator) operation(out *bytes.Buffer, method string, path string, item *pathItem, op *operation) error {
+	name := op.OperationID
+	if name == "" {
+		name = strings.ToLower(method) + " " + pathParamPattern.ReplaceAllString(path, "by $1")
+	}
+	name = exportedName(name)
+
+	params, err := gen.params(name, append(append([]*parameter(nil), item.Parameters...), op.Parameters...))
+	if err != nil {
+		return err
+	}
+
+	pathParams := map[string]param{}
+	var queryParams []param
+	for _, p := range params {
+		switch p.in {
+		case "path":
+			pathParams[p.name] = p
+		case "query", "header":
+			queryParams = append(queryParams, p)
+		}
+	}
+
+	pathExpression, orderedPathParams, err := pathExpression(path, pathParams)
+	if err != nil {
+		return err
+	}
+
+	var arguments []string
+	arguments = append(arguments, "ctx context.Context")
+	for _, p := range orderedPathParams {
+		arguments = append(arguments, p.goName+" "+p.goType)
+	}
+
+	paramsType := name + "Params"
+	if len(queryParams) > 0 {
+		var declaration strings.Builder
+		fmt.Fprintf(&declaration, "// %s holds the query and header parameters of %s.\ntype %s struct {\n", paramsType, name, paramsType)
+		for _, p := range queryParams {
+			goType := p.goType
+			if !p.required && !p.array {
+				goType = "*" + goType
+			}
+			fmt.Fprintf(&declaration, "%s %s\n", exportedName(p.name), goType)
+		}
+		declaration.WriteString("}\n")
+		if _, exists := gen.types[paramsType]; exists {
+			return fmt.Errorf("type %s already declared", paramsType)
+		}
+		gen.types[paramsType] = declaration.String()
+		gen.typeNames = append(gen.typeNames, paramsType)
+		sort.Strings(gen.typeNames)
+		arguments = append(arguments, "params "+paramsType)
+	}
+
+	body, err := gen.doc.requestBody(op.RequestBody)
+	if err != nil {
+		return err
+	}
+	hasBody := false
+	if body != nil {
+		bodySchema := jsonSchema(body.Content)
+		if bodySchema == nil {
+			return fmt.Errorf("request body has no application/json content")
+		}
+		bodyType, err := gen.goType(name+"Request", bodySchema)
+		if err != nil {
+			return err
+		}
+		arguments = append(arguments, "body "+bodyType)
+		hasBody = true
+	}
+
+	resultType, errorType, err := gen.responses(name, op.Responses)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "// %s sends %s %s.\n", name, method, path)
+	if summary := firstNonEmpty(op.Summary, op.Description); summary != "" {
+		fmt.Fprintf(out, "//\n// %s\n", singleLine(summary))
+	}
+	if op.Deprecated {
+		fmt.Fprintln(out, "//\n// Deprecated: the operation is deprecated in the spec.")
+	}
+
+	returns := "*httperror.HTTPError"
+	if resultType != "" {
+		returns = "(" + gen.returnType(resultType) + ", *httperror.HTTPError)"
+	}
+	fmt.Fprintf(out, "func (client *Client) %s(%s) %s {\n", name, strings.Join(arguments, ", "), returns)
+
+	if len(queryParams) > 0 {
+		writeParams(out, queryParams)
+	}
+	if resultType != "" {
+		fmt.Fprintf(out, "var result %s\n", resultType)
+	}
+
+	fmt.Fprintf(out, "httpError := client.execute(ctx, call{\nmethod: %s,\npath: %s,\n", methodConstants[method], pathExpression)
+	if len(queryParams) > 0 {
+		fmt.Fprintln(out, "query: query,\nheaders: headers,")
+	}
+	if hasBody {
+		fmt.Fprintln(out, "body: body,")
+	}
+	if resultType != "" {
+		fmt.Fprintln(out, "result: &result,")
+	}
+	if errorType != "" {
+		fmt.Fprintf(out, "newError: newResponseError[%s],\n", errorType)
+	}
+	fmt.Fprintln(out, "})")
+
+	switch {
+	case resultType == "":
+		fmt.Fprintln(out, "return httpError")
+	case gen.returnType(resultType) != resultType:
+		fmt.Fprintln(out, "if httpError != nil {\nreturn nil, httpError\n}\nreturn &result, nil")
+	default:
+		fmt.Fprintln(out, "return result, httpError")
+	}
+	fmt.Fprintln(out, "}")
+	return nil
+}
+
+func (gen *generator) params(operationName string, parameters []*parameter) ([]param, error) {
+	var params []param
+	seen := map[string]int{}
+	for _, raw := range parameters {
+		p, err := gen.doc.parameter(raw)
+		if err != nil {
+			return nil, err
+		}
+		if p.In == "cookie" {
+			continue
+		}
+
+		goType, err := gen.goType(operationName+exportedName(p.Name), p.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		resolved := param{
+			name:     p.Name,
+			goName:   unexportedName(p.Name),
+			goType:   goType,
+			in:       p.In,
+			required: p.Required || p.In == "path",
+			array:    strings.HasPrefix(goType, "[]"),
+		}
+
+		// Operation parameters override path item parameters.
+		key := p.In + ":" + p.Name
+		if index, ok := seen[key]; ok {
+			params[index] = resolved
+			continue
+		}
+		seen[key] = len(params)
+		params = append(params, resolved)
+	}
+	return params, nil
+}
+
+// responses picks the JSON schema of the first 2xx response as result and
+// the schema of "default" or the first error response as error body.
+func (gen *generator) responses(operationName string, responses map[string]*response) (string, string, error) {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var resultType, errorType, fallbackErrorType string
+	for _, code := range codes {
+		resp, err := gen.doc.response(responses[code])
+		if err != nil {
+			return "", "", err
+		}
+		responseSchema := jsonSchema(resp.Content)
+		if responseSchema == nil {
+			continue
+		}
+
+		success := strings.HasPrefix(code, "2")
+		switch {
+		case success && resultType == "":
+			resultType, err = gen.goType(operationName+"Response", responseSchema)
+		case code == "default":
+			errorType, err = gen.goType(operationName+"Error", responseSchema)
+		case !success && fallbackErrorType == "":
+			fallbackErrorType, err = gen.goType(operationName+"Error", responseSchema)
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("response %s: %w", code, err)
+		}
+	}
+
+	if errorType == "" {
+		errorType = fallbackErrorType
+	}
+	return resultType, errorType, nil
+}
+
+// pathExpression turns /pets/{petId} into a Go expression and returns the
+// path parameters in the order they appear.
+func pathExpression(path string, params map[string]param) (string, []param, error) {
+	var parts []string
+	var ordered []param
+	last := 0
+	for _, match := range pathParamPattern.FindAllStringSubmatchIndex(path, -1) {
+		name := path[match[2]:match[3]]
+		p, ok := params[name]
+		if !ok {
+			return "", nil, fmt.Errorf("path parameter %s is not declared", name)
+		}
+		if literal := path[last:match[0]]; literal != "" {
+			parts = append(parts, strconv.Quote(literal))
+		}
+		parts = append(parts, fmt.Sprintf("url.PathEscape(fmt.Sprint(%s))", p.goName))
+		ordered = append(ordered, p)
+		last = match[1]
+	}
+	if literal := path[last:]; literal != "" || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(literal))
+	}
+
+	for name := range params {
+		if !strings.Contains(path, "{"+name+"}") {
+			return "", nil, fmt.Errorf("path parameter %s does not appear in the path", name)
+		}
+	}
+	return strings.Join(parts, " + "), ordered, nil
+}
+
+func writeParams(out *bytes.Buffer, params []param) {
+	fmt.Fprintln(out, "query := url.Values{}\nheaders := http.Header{}")
+	for _, p := range params {
+		target := "query"
+		if p.in == "header" {
+			target = "headers"
+		}
+		field := "params." + exportedName(p.name)
+
+		switch {
+		case p.array:
+			fmt.Fprintf(out, "for _, value := range %s {\n%s.Add(%q, fmt.Sprint(value))\n}\n", field, target, p.name)
+		case p.required:
+			fmt.Fprintf(out, "%s.Set(%q, fmt.Sprint(%s))\n", target, p.name, field)
+		default:
+			fmt.Fprintf(out, "if %s != nil {\n%s.Set(%q, fmt.Sprint(*%s))\n}\n", field, target, p.name, field)
+		}
+	}
+}
+
+// returnType returns structs by pointer and everything else by value.
+func (gen *generator) returnType(goType string) string {
+	if goType == "" || gen.isReference(goType) || isBuiltin(goType) {
+		return goType
+	}
+	if underlying, ok := gen.underlying[goType]; ok && isBuiltin(underlying) {
+		return goType
+	}
+	return "*" + goType
+}
+
+func isBuiltin(goType string) bool {
+	switch goType {
+	case "string", "int", "int32", "int64", "float32", "float64", "bool":
+		return true
+	}
+	return false
+}
+
+func writeComment(out interface{ WriteString(string) (int, error) }, name string, text string) {
+	_, _ = out.WriteString("// " + name + " ...\n")
+	if text != "" {
+		_, _ = out.WriteString("//\n// " + singleLine(text) + "\n")
+	}
+}
+
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+const header = `import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httperror"
+)
+
+// Client ...
+type Client struct {
+	client  *httpclient.Client
+	decoder responseDecoder
+}
+
+type responseDecoder interface {
+	DecodeResponseBody(ctx context.Context, response *http.Response, target interface{}) *httperror.HTTPError
+}
+
+// NewClient wraps client, which should have its base URL set. Bodies are
+// decoded with the decoder of client when it decodes response bodies, and
+// with an httpdecoder.Decoder otherwise.
+func NewClient(client *httpclient.Client) *Client {
+	decoder, ok := client.Decoder().(responseDecoder)
+	if !ok {
+		decoder = httpdecoder.NewHTTPDecoder()
+	}
+	return &Client{client: client, decoder: decoder}
+}
+
+// ResponseError is the Err of the HTTPError returned for error responses
+// that have a body schema. Unwrap it with errors.As.
+type ResponseError[T any] struct {
+	Body T
+}
+
+// Error ...
+func (err *ResponseError[T]) Error() string {
+	return fmt.Sprintf("error response: %+v", err.Body)
+}
+
+func (err *ResponseError[T]) target() interface{} {
+	return &err.Body
+}
+
+type responseError interface {
+	error
+	target() interface{}
+}
+
+func newResponseError[T any]() responseError {
+	return &ResponseError[T]{}
+}
+
+type call struct {
+	method   string
+	path     string
+	query    url.Values
+	headers  http.Header
+	body     interface{}
+	result   interface{}
+	newError func() responseError
+}
+
+// hasBody reports whether body is set. A nil pointer, map or slice in body is
+// not, so an unset optional body is left out instead of sent as null.
+func hasBody(body interface{}) bool {
+	if body == nil {
+		return false
+	}
+	value := reflect.ValueOf(body)
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		return !value.IsNil()
+	}
+	return true
+}
+
+func (client *Client) execute(ctx context.Context, call call) *httperror.HTTPError {
+	endpoint := call.path
+	if len(call.query) > 0 {
+		endpoint += "?" + call.query.Encode()
+	}
+
+	builder := client.client.NewRequestBuilder(ctx).
+		WithMethod(call.method).
+		WithEndpoint(endpoint)
+	for key, values := range call.headers {
+		for _, value := range values {
+			builder = builder.AddHeader(key, value)
+		}
+	}
+	if hasBody(call.body) {
+		builder = builder.WithBody(call.body)
+	}
+
+	request, httpError := builder.Build()
+	if httpError != nil {
+		return httpError
+	}
+	response, httpError := client.client.ExecuteRequest(request)
+	if httpError != nil {
+		return httpError
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		failure := &httperror.HTTPError{
+			Status:  response.StatusCode,
+			Message: "unexpected response status",
+			Time:    time.Now(),
+		}
+		if call.newError == nil {
+			if response.Body != nil {
+				_ = response.Body.Close()
+			}
+			return failure
+		}
+		errorBody := call.newError()
+		if decodeError := client.decoder.DecodeResponseBody(ctx, response, errorBody.target()); decodeError != nil {
+			failure.Err = decodeError
+			return failure
+		}
+		failure.Err = errorBody
+		return failure
+	}
+
+	if call.result == nil || response.StatusCode == http.StatusNoContent {
+		if response.Body != nil {
+			_ = response.Body.Close()
+		}
+		return nil
+	}
+	return client.decoder.DecodeResponseBody(ctx, response, call.result)
+}
+`
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// The petstore golden file is a real package, so go build and its own tests
+// also check that the generated code compiles and works.
+func TestGenerate_Golden(t *testing.T) {
+	doc, err := loadDocument(filepath.Join("testdata", "petstore.yaml"))
+	assert.NoError(t, err)
+
+	code, err := generate(doc, "petstore")
+	assert.NoError(t, err)
+
+	golden := filepath.Join("internal", "petstore", "petstore.gen.go")
+	if *update {
+		assert.NoError(t, os.WriteFile(golden, code, 0o644))
+	}
+	expected, err := os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(code))
+}
+
+func TestGenerate_JSONSpec(t *testing.T) {
+	doc, err := parseDocument([]byte(`{
+		"openapi": "3.1.0",
+		"info": {"title": "Users", "version": "2"},
+		"paths": {"/users/{id}": {"get": {
+			"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+			"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "string"}}}}}
+		}}}
+	}`))
+	assert.NoError(t, err)
+
+	code, err := generate(doc, "users")
+	assert.NoError(t, err)
+	assert.Contains(t, string(code), "func (client *Client) GetUsersByID(ctx context.Context, id string) (string, *httperror.HTTPError) {")
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := map[string]string{
+		"openapi: 2.0\n": `unsupported openapi version "2.0", want 3.x`,
+		`openapi: 3.0.0
+paths:
+  /users/{id}:
+    get:
+      responses: {}
+`: "GET /users/{id}: path parameter id is not declared",
+		`openapi: 3.0.0
+paths:
+  /users:
+    get:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      responses: {}
+`: "GET /users: path parameter id does not appear in the path",
+		`openapi: 3.0.0
+paths:
+  /users:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+`: `GET /users: response 200: unknown schema "#/components/schemas/User"`,
+	}
+
+	for spec, message := range tests {
+		doc, err := parseDocument([]byte(spec))
+		if err == nil {
+			_, err = generate(doc, "client")
+		}
+		assert.EqualError(t, err, message)
+	}
+}
@@ -0,0 +1,3 @@
+package petstore
+
+//go:generate go run ../.. -spec ../../testdata/petstore.yaml -package petstore -out petstore.gen.go
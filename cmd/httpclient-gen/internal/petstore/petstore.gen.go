@@ -0,0 +1,294 @@
+// Code generated by httpclient-gen. DO NOT EDIT.
+
+// Package petstore is a client for Petstore 1.0.0.
+package petstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httperror"
+)
+
+// Client ...
+type Client struct {
+	client  *httpclient.Client
+	decoder responseDecoder
+}
+
+type responseDecoder interface {
+	DecodeResponseBody(ctx context.Context, response *http.Response, target interface{}) *httperror.HTTPError
+}
+
+// NewClient wraps client, which should have its base URL set. Bodies are
+// decoded with the decoder of client when it decodes response bodies, and
+// with an httpdecoder.Decoder otherwise.
+func NewClient(client *httpclient.Client) *Client {
+	decoder, ok := client.Decoder().(responseDecoder)
+	if !ok {
+		decoder = httpdecoder.NewHTTPDecoder()
+	}
+	return &Client{client: client, decoder: decoder}
+}
+
+// ResponseError is the Err of the HTTPError returned for error responses
+// that have a body schema. Unwrap it with errors.As.
+type ResponseError[T any] struct {
+	Body T
+}
+
+// Error ...
+func (err *ResponseError[T]) Error() string {
+	return fmt.Sprintf("error response: %+v", err.Body)
+}
+
+func (err *ResponseError[T]) target() interface{} {
+	return &err.Body
+}
+
+type responseError interface {
+	error
+	target() interface{}
+}
+
+func newResponseError[T any]() responseError {
+	return &ResponseError[T]{}
+}
+
+type call struct {
+	method   string
+	path     string
+	query    url.Values
+	headers  http.Header
+	body     interface{}
+	result   interface{}
+	newError func() responseError
+}
+
+// hasBody reports whether body is set. A nil pointer, map or slice in body is
+// not, so an unset optional body is left out instead of sent as null.
+func hasBody(body interface{}) bool {
+	if body == nil {
+		return false
+	}
+	value := reflect.ValueOf(body)
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		return !value.IsNil()
+	}
+	return true
+}
+
+func (client *Client) execute(ctx context.Context, call call) *httperror.HTTPError {
+	endpoint := call.path
+	if len(call.query) > 0 {
+		endpoint += "?" + call.query.Encode()
+	}
+
+	builder := client.client.NewRequestBuilder(ctx).
+		WithMethod(call.method).
+		WithEndpoint(endpoint)
+	for key, values := range call.headers {
+		for _, value := range values {
+			builder = builder.AddHeader(key, value)
+		}
+	}
+	if hasBody(call.body) {
+		builder = builder.WithBody(call.body)
+	}
+
+	request, httpError := builder.Build()
+	if httpError != nil {
+		return httpError
+	}
+	response, httpError := client.client.ExecuteRequest(request)
+	if httpError != nil {
+		return httpError
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		failure := &httperror.HTTPError{
+			Status:  response.StatusCode,
+			Message: "unexpected response status",
+			Time:    time.Now(),
+		}
+		if call.newError == nil {
+			if response.Body != nil {
+				_ = response.Body.Close()
+			}
+			return failure
+		}
+		errorBody := call.newError()
+		if decodeError := client.decoder.DecodeResponseBody(ctx, response, errorBody.target()); decodeError != nil {
+			failure.Err = decodeError
+			return failure
+		}
+		failure.Err = errorBody
+		return failure
+	}
+
+	if call.result == nil || response.StatusCode == http.StatusNoContent {
+		if response.Body != nil {
+			_ = response.Body.Close()
+		}
+		return nil
+	}
+	return client.decoder.DecodeResponseBody(ctx, response, call.result)
+}
+
+// ServerURL is the first server of the spec, for httpclient.WithBaseURL.
+const ServerURL = "https://petstore.rain.us/v1"
+
+// Error ...
+type Error struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message"`
+}
+
+// GetPetsByPetIDStatsParams holds the query and header parameters of GetPetsByPetIDStats.
+type GetPetsByPetIDStatsParams struct {
+	Since string
+}
+
+// ListPetsParams holds the query and header parameters of ListPets.
+type ListPetsParams struct {
+	Limit    *int32
+	Tags     []string
+	XTraceID *string
+}
+
+// NewPet ...
+type NewPet struct {
+	Name  string       `json:"name"`
+	Owner *NewPetOwner `json:"owner,omitempty"`
+	// Free-form label.
+	Tag *string `json:"tag,omitempty"`
+}
+
+// NewPetOwner ...
+type NewPetOwner struct {
+	Email *string `json:"email,omitempty"`
+}
+
+// Pet ...
+//
+// A pet in the store.
+type Pet struct {
+	NewPet
+	ID     int64             `json:"id"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Weight *float64          `json:"weight,omitempty"`
+}
+
+// Pets ...
+type Pets []Pet
+
+// UpdatePetRequest ...
+type UpdatePetRequest struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// ListPets sends GET /pets.
+//
+// List all pets.
+func (client *Client) ListPets(ctx context.Context, params ListPetsParams) (Pets, *httperror.HTTPError) {
+	query := url.Values{}
+	headers := http.Header{}
+	if params.Limit != nil {
+		query.Set("limit", fmt.Sprint(*params.Limit))
+	}
+	for _, value := range params.Tags {
+		query.Add("tags", fmt.Sprint(value))
+	}
+	if params.XTraceID != nil {
+		headers.Set("X-Trace-ID", fmt.Sprint(*params.XTraceID))
+	}
+	var result Pets
+	httpError := client.execute(ctx, call{
+		method:   http.MethodGet,
+		path:     "/pets",
+		query:    query,
+		headers:  headers,
+		result:   &result,
+		newError: newResponseError[Error],
+	})
+	return result, httpError
+}
+
+// CreatePet sends POST /pets.
+//
+// Create a pet.
+func (client *Client) CreatePet(ctx context.Context, body NewPet) (*Pet, *httperror.HTTPError) {
+	var result Pet
+	httpError := client.execute(ctx, call{
+		method:   http.MethodPost,
+		path:     "/pets",
+		body:     body,
+		result:   &result,
+		newError: newResponseError[Error],
+	})
+	if httpError != nil {
+		return nil, httpError
+	}
+	return &result, nil
+}
+
+// ShowPetByID sends GET /pets/{petId}.
+//
+// Info for a specific pet.
+func (client *Client) ShowPetByID(ctx context.Context, petID int64) (*Pet, *httperror.HTTPError) {
+	var result Pet
+	httpError := client.execute(ctx, call{
+		method:   http.MethodGet,
+		path:     "/pets/" + url.PathEscape(fmt.Sprint(petID)),
+		result:   &result,
+		newError: newResponseError[Error],
+	})
+	if httpError != nil {
+		return nil, httpError
+	}
+	return &result, nil
+}
+
+// UpdatePet sends PATCH /pets/{petId}.
+//
+// Deprecated: the operation is deprecated in the spec.
+func (client *Client) UpdatePet(ctx context.Context, petID int64, body UpdatePetRequest) *httperror.HTTPError {
+	httpError := client.execute(ctx, call{
+		method: http.MethodPatch,
+		path:   "/pets/" + url.PathEscape(fmt.Sprint(petID)),
+		body:   body,
+	})
+	return httpError
+}
+
+// DeletePet sends DELETE /pets/{petId}.
+func (client *Client) DeletePet(ctx context.Context, petID int64) *httperror.HTTPError {
+	httpError := client.execute(ctx, call{
+		method: http.MethodDelete,
+		path:   "/pets/" + url.PathEscape(fmt.Sprint(petID)),
+	})
+	return httpError
+}
+
+// GetPetsByPetIDStats sends GET /pets/{petId}/stats.
+func (client *Client) GetPetsByPetIDStats(ctx context.Context, petID int64, params GetPetsByPetIDStatsParams) (map[string]int, *httperror.HTTPError) {
+	query := url.Values{}
+	headers := http.Header{}
+	query.Set("since", fmt.Sprint(params.Since))
+	var result map[string]int
+	httpError := client.execute(ctx, call{
+		method:  http.MethodGet,
+		path:    "/pets/" + url.PathEscape(fmt.Sprint(petID)) + "/stats",
+		query:   query,
+		headers: headers,
+		result:  &result,
+	})
+	return result, httpError
+}
@@ -0,0 +1,145 @@
+package petstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httperror"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(httpclient.NewHTTPClient(httpclient.WithBaseURL(server.URL + "/v1")))
+}
+
+func TestClient_ListPets(t *testing.T) {
+	client := newTestClient(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/v1/pets", request.URL.Path)
+		assert.Equal(t, "limit=2&tags=cat&tags=dog", request.URL.RawQuery)
+		assert.Equal(t, "abc", request.Header.Get("X-Trace-ID"))
+		_, _ = writer.Write([]byte(`[{"id":1,"name":"rain","labels":{"color":"grey"}},{"id":2,"name":"snow"}]`))
+	})
+
+	limit := int32(2)
+	traceID := "abc"
+	pets, httpError := client.ListPets(context.Background(), ListPetsParams{
+		Limit:    &limit,
+		Tags:     []string{"cat", "dog"},
+		XTraceID: &traceID,
+	})
+
+	assert.Nil(t, httpError)
+	assert.Len(t, pets, 2)
+	assert.Equal(t, int64(1), pets[0].ID)
+	assert.Equal(t, "rain", pets[0].Name)
+	assert.Equal(t, "grey", pets[0].Labels["color"])
+}
+
+func TestClient_CreatePet(t *testing.T) {
+	client := newTestClient(t, func(writer http.ResponseWriter, request *http.Request) {
+		var pet NewPet
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&pet))
+		assert.Equal(t, http.MethodPost, request.Method)
+		writer.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(writer).Encode(Pet{NewPet: pet, ID: 7})
+	})
+
+	pet, httpError := client.CreatePet(context.Background(), NewPet{Name: "rain"})
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, int64(7), pet.ID)
+	assert.Equal(t, "rain", pet.Name)
+}
+
+func TestClient_NilBody(t *testing.T) {
+	client := newTestClient(t, func(writer http.ResponseWriter, request *http.Request) {
+		body, err := io.ReadAll(request.Body)
+		assert.NoError(t, err)
+		assert.Empty(t, body)
+		writer.WriteHeader(http.StatusNoContent)
+	})
+
+	httpError := client.execute(context.Background(), call{
+		method: http.MethodPatch,
+		path:   "/pets/1",
+		body:   (*UpdatePetRequest)(nil),
+	})
+
+	assert.Nil(t, httpError)
+}
+
+func TestClient_TypedErrorBody(t *testing.T) {
+	client := newTestClient(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/v1/pets/42", request.URL.Path)
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte(`{"code":404,"message":"pet not found"}`))
+	})
+
+	pet, httpError := client.ShowPetByID(context.Background(), 42)
+
+	assert.Nil(t, pet)
+	assert.Equal(t, http.StatusNotFound, httpError.Status)
+	assert.False(t, httpError.Time.IsZero())
+	var responseError *ResponseError[Error]
+	assert.True(t, errors.As(httpError, &responseError))
+	assert.Equal(t, Error{Code: 404, Message: "pet not found"}, responseError.Body)
+}
+
+type countingDecoder struct {
+	*httpdecoder.Decoder
+	calls int
+}
+
+func (decoder *countingDecoder) DecodeResponseBody(ctx context.Context, response *http.Response, target interface{}) *httperror.HTTPError {
+	decoder.calls++
+	return decoder.Decoder.DecodeResponseBody(ctx, response, target)
+}
+
+func TestClient_ConfiguredDecoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte(`{"code":404,"message":"pet not found"}`))
+	}))
+	defer server.Close()
+	decoder := &countingDecoder{Decoder: httpdecoder.NewHTTPDecoder()}
+	client := NewClient(httpclient.NewHTTPClient(httpclient.WithBaseURL(server.URL), httpclient.WithDecoder(decoder)))
+
+	_, httpError := client.ShowPetByID(context.Background(), 42)
+
+	var responseError *ResponseError[Error]
+	assert.True(t, errors.As(httpError, &responseError))
+	assert.Equal(t, "pet not found", responseError.Body.Message)
+	assert.Equal(t, 1, decoder.calls)
+}
+
+func TestClient_NoContent(t *testing.T) {
+	client := newTestClient(t, func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		assert.Equal(t, http.MethodPatch, request.Method)
+		assert.JSONEq(t, `{"name":"snow"}`, string(body))
+		writer.WriteHeader(http.StatusNoContent)
+	})
+
+	name := "snow"
+	assert.Nil(t, client.UpdatePet(context.Background(), 1, UpdatePetRequest{Name: &name}))
+}
+
+func TestClient_UntypedError(t *testing.T) {
+	client := newTestClient(t, func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusConflict)
+	})
+
+	httpError := client.DeletePet(context.Background(), 1)
+
+	assert.Equal(t, http.StatusConflict, httpError.Status)
+	assert.Equal(t, "unexpected response status", httpError.Message)
+}
@@ -0,0 +1,44 @@
+// Command httpclient-gen generates a typed client on top of
+// httpclient.Client from an OpenAPI 3 document in YAML or JSON.
+//
+//	//go:generate go run github.com/ttanik/http-client/cmd/httpclient-gen -spec petstore.yaml -package petstore -out client.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	specPath := flag.String("spec", "", "OpenAPI 3 document, YAML or JSON")
+	packageName := flag.String("package", "client", "package of the generated file")
+	outPath := flag.String("out", "", "output file, stdout when empty")
+	flag.Parse()
+
+	if err := run(*specPath, *packageName, *outPath); err != nil {
+		fmt.Fprintln(os.Stderr, "httpclient-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath string, packageName string, outPath string) error {
+	if specPath == "" {
+		return fmt.Errorf("-spec is required")
+	}
+
+	doc, err := loadDocument(specPath)
+	if err != nil {
+		return err
+	}
+	code, err := generate(doc, packageName)
+	if err != nil {
+		return err
+	}
+
+	if outPath == "" {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return os.WriteFile(outPath, code, 0o644)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+var initialisms = map[string]bool{
+	"API":  true,
+	"HTML": true,
+	"HTTP": true,
+	"ID":   true,
+	"IP":   true,
+	"JSON": true,
+	"SQL":  true,
+	"URI":  true,
+	"URL":  true,
+	"UUID": true,
+	"XML":  true,
+}
+
+var keywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+}
+
+// words splits identifiers like pet_id, petId, X-Request-ID and PetID into
+// their words.
+func words(name string) []string {
+	var result []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, string(current))
+			current = nil
+		}
+	}
+
+	runes := []rune(name)
+	for index, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(current) > 0 {
+			previous := runes[index-1]
+			nextIsLower := index+1 < len(runes) && unicode.IsLower(runes[index+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+	return result
+}
+
+// exportedName turns a spec name into an exported Go identifier.
+func exportedName(name string) string {
+	var builder strings.Builder
+	for _, word := range words(name) {
+		upper := strings.ToUpper(word)
+		if initialisms[upper] {
+			builder.WriteString(upper)
+			continue
+		}
+		builder.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+	}
+
+	identifier := builder.String()
+	if identifier == "" {
+		return "X"
+	}
+	if unicode.IsDigit(rune(identifier[0])) {
+		return "X" + identifier
+	}
+	return identifier
+}
+
+// unexportedName turns a spec name into a Go parameter name.
+func unexportedName(name string) string {
+	exported := exportedName(name)
+	parts := words(exported)
+	first := parts[0]
+	if initialisms[strings.ToUpper(first)] || strings.ToUpper(first) == first {
+		first = strings.ToLower(first)
+	} else {
+		first = strings.ToLower(first[:1]) + first[1:]
+	}
+
+	identifier := first + strings.TrimPrefix(exported, parts[0])
+	if keywords[identifier] {
+		return identifier + "Param"
+	}
+	return identifier
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportedName(t *testing.T) {
+	assert.Equal(t, "PetID", exportedName("pet_id"))
+	assert.Equal(t, "PetID", exportedName("petId"))
+	assert.Equal(t, "XRequestID", exportedName("X-Request-ID"))
+	assert.Equal(t, "HTTPStatus", exportedName("HTTPStatus"))
+	assert.Equal(t, "ShowPetByID", exportedName("showPetById"))
+	assert.Equal(t, "X2fa", exportedName("2fa"))
+}
+
+func TestUnexportedName(t *testing.T) {
+	assert.Equal(t, "petID", unexportedName("petId"))
+	assert.Equal(t, "id", unexportedName("id"))
+	assert.Equal(t, "xRequestID", unexportedName("X-Request-ID"))
+	assert.Equal(t, "typeParam", unexportedName("type"))
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The subset of OpenAPI 3 the generator understands. JSON documents are
+// valid YAML, so both go through the YAML decoder.
+
+type document struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Info       info                 `yaml:"info"`
+	Servers    []server             `yaml:"servers"`
+	Paths      map[string]*pathItem `yaml:"paths"`
+	Components components           `yaml:"components"`
+}
+
+type info struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+type server struct {
+	URL string `yaml:"url"`
+}
+
+type components struct {
+	Schemas       map[string]*schema      `yaml:"schemas"`
+	Parameters    map[string]*parameter   `yaml:"parameters"`
+	RequestBodies map[string]*requestBody `yaml:"requestBodies"`
+	Responses     map[string]*response    `yaml:"responses"`
+}
+
+type pathItem struct {
+	Parameters []*parameter `yaml:"parameters"`
+	Get        *operation   `yaml:"get"`
+	Post       *operation   `yaml:"post"`
+	Put        *operation   `yaml:"put"`
+	Patch      *operation   `yaml:"patch"`
+	Delete     *operation   `yaml:"delete"`
+}
+
+type operation struct {
+	OperationID string               `yaml:"operationId"`
+	Summary     string               `yaml:"summary"`
+	Description string               `yaml:"description"`
+	Deprecated  bool                 `yaml:"deprecated"`
+	Parameters  []*parameter         `yaml:"parameters"`
+	RequestBody *requestBody         `yaml:"requestBody"`
+	Responses   map[string]*response `yaml:"responses"`
+}
+
+type parameter struct {
+	Ref         string  `yaml:"$ref"`
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description"`
+	Required    bool    `yaml:"required"`
+	Schema      *schema `yaml:"schema"`
+}
+
+type requestBody struct {
+	Ref      string                `yaml:"$ref"`
+	Required bool                  `yaml:"required"`
+	Content  map[string]*mediaType `yaml:"content"`
+}
+
+type response struct {
+	Ref         string                `yaml:"$ref"`
+	Description string                `yaml:"description"`
+	Content     map[string]*mediaType `yaml:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref                  string                `yaml:"$ref"`
+	Type                 string                `yaml:"type"`
+	Format               string                `yaml:"format"`
+	Description          string                `yaml:"description"`
+	Properties           map[string]*schema    `yaml:"properties"`
+	Required             []string              `yaml:"required"`
+	Items                *schema               `yaml:"items"`
+	AdditionalProperties *additionalProperties `yaml:"additionalProperties"`
+	Nullable             bool                  `yaml:"nullable"`
+	AllOf                []*schema             `yaml:"allOf"`
+}
+
+// additionalProperties is either a boolean or a schema.
+type additionalProperties struct {
+	allowed bool
+	schema  *schema
+}
+
+// UnmarshalYAML ...
+func (properties *additionalProperties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&properties.allowed)
+	}
+	properties.allowed = true
+	return node.Decode(&properties.schema)
+}
+
+func loadDocument(path string) (*document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseDocument(content)
+}
+
+func parseDocument(content []byte) (*document, error) {
+	var doc document
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("parsing spec: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported openapi version %q, want 3.x", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// refName returns the component name of a local reference such as
+// #/components/schemas/Pet.
+func refName(ref string, kind string) (string, error) {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf("unsupported reference %q", ref)
+	}
+	return strings.TrimPrefix(ref, prefix), nil
+}
+
+func (doc *document) parameter(param *parameter) (*parameter, error) {
+	if param.Ref == "" {
+		return param, nil
+	}
+	name, err := refName(param.Ref, "parameters")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := doc.Components.Parameters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown parameter %q", param.Ref)
+	}
+	return resolved, nil
+}
+
+func (doc *document) requestBody(body *requestBody) (*requestBody, error) {
+	if body == nil || body.Ref == "" {
+		return body, nil
+	}
+	name, err := refName(body.Ref, "requestBodies")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := doc.Components.RequestBodies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown request body %q", body.Ref)
+	}
+	return resolved, nil
+}
+
+func (doc *document) response(resp *response) (*response, error) {
+	if resp.Ref == "" {
+		return resp, nil
+	}
+	name, err := refName(resp.Ref, "responses")
+	if err != nil {
+		return nil, err
+	}
+	resolved, ok := doc.Components.Responses[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown response %q", resp.Ref)
+	}
+	return resolved, nil
+}
+
+// jsonSchema returns the schema of the JSON content, if any.
+func jsonSchema(content map[string]*mediaType) *schema {
+	for contentType, media := range content {
+		if media != nil && (contentType == "application/json" || strings.HasSuffix(contentType, "+json")) {
+			return media.Schema
+		}
+	}
+	return nil
+}
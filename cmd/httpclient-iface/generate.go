@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var requiredImports = []string{
+	`"context"`,
+	`"fmt"`,
+	`"net/http"`,
+	`"time"`,
+	`"github.com/ttanik/http-client/httpclient"`,
+	`"github.com/ttanik/http-client/httpdecoder"`,
+	`"github.com/ttanik/http-client/httperror"`,
+}
+
+// generate renders the implementation of svc.
+func generate(svc *service) ([]byte, error) {
+	implementation := unexported(svc.name) + "Client"
+	var out bytes.Buffer
+
+	fmt.Fprintf(&out, "// Code generated by httpclient-iface. DO NOT EDIT.\n\npackage %s\n\n", svc.packageName)
+	writeImports(&out, append(append([]string(nil), requiredImports...), svc.imports...))
+
+	fmt.Fprintf(&out, `type %[1]s struct {
+	client  *httpclient.Client
+	decoder *httpdecoder.Decoder
+}
+
+// New%[2]s implements %[2]s on top of client.
+func New%[2]s(client *httpclient.Client) %[2]s {
+	return &%[1]s{client: client, decoder: httpdecoder.NewHTTPDecoder()}
+}
+
+func (service *%[1]s) execute(ctx context.Context, builder httpclient.HTTPRequestBuilder, result interface{}) *httperror.HTTPError {
+	request, httpError := builder.Build()
+	if httpError != nil {
+		return httpError
+	}
+	response, httpError := service.client.ExecuteRequest(request)
+	if httpError != nil {
+		return httpError
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return &httperror.HTTPError{
+			Status:  response.StatusCode,
+			Message: "unexpected response status",
+			Err:     service.decoder.DecodeErrorBody(ctx, response),
+			Time:    time.Now(),
+		}
+	}
+	if result == nil || response.StatusCode == http.StatusNoContent {
+		if response.Body != nil {
+			_ = response.Body.Close()
+		}
+		return nil
+	}
+	return service.decoder.DecodeResponseBody(ctx, response, result)
+}
+`, implementation, svc.name)
+
+	for _, m := range svc.methods {
+		writeMethod(&out, implementation, m)
+	}
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, out.Bytes())
+	}
+	return formatted, nil
+}
+
+// writeImports groups the standard library first, like goimports.
+func writeImports(out *bytes.Buffer, imports []string) {
+	seen := map[string]bool{}
+	var standard, others []string
+	for _, spec := range imports {
+		if seen[spec] {
+			continue
+		}
+		seen[spec] = true
+
+		path := spec[strings.Index(spec, `"`)+1:]
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			others = append(others, spec)
+		} else {
+			standard = append(standard, spec)
+		}
+	}
+	sort.Slice(standard, func(i, j int) bool { return importPath(standard[i]) < importPath(standard[j]) })
+	sort.Slice(others, func(i, j int) bool { return importPath(others[i]) < importPath(others[j]) })
+
+	fmt.Fprintln(out, "import (")
+	for _, spec := range standard {
+		fmt.Fprintln(out, spec)
+	}
+	if len(standard) > 0 && len(others) > 0 {
+		fmt.Fprintln(out)
+	}
+	for _, spec := range others {
+		fmt.Fprintln(out, spec)
+	}
+	fmt.Fprint(out, ")\n\n")
+}
+
+func importPath(spec string) string {
+	return spec[strings.Index(spec, `"`):]
+}
+
+// writeMethod prefixes the locals of the method with gen so that they cannot
+// clash with parameter names.
+func writeMethod(out *bytes.Buffer, implementation string, m *method) {
+	fmt.Fprintf(out, "\n// %s ...\nfunc (genService *%s) %s {\n", m.name, implementation, m.signature)
+
+	ctx := m.params[0].name
+	fmt.Fprintf(out, "genBuilder := genService.client.NewRequestBuilder(%s).\nWithMethod(%s).\nWithEndpoint(%q)\n", ctx, httpMethods[m.httpMethod], m.path)
+
+	for _, p := range m.params {
+		var call string
+		switch p.role {
+		case "path":
+			call = "WithPathParam"
+		case "query":
+			call = "WithQueryParam"
+		case "header":
+			call = "AddHeader"
+		case "body":
+			fmt.Fprintf(out, "genBuilder = genBuilder.WithBody(%s)\n", p.name)
+			continue
+		default:
+			continue
+		}
+
+		switch {
+		case p.slice:
+			fmt.Fprintf(out, "for _, genValue := range %s {\ngenBuilder = genBuilder.%s(%q, fmt.Sprint(genValue))\n}\n", p.name, call, p.key)
+		case p.optional:
+			fmt.Fprintf(out, "if %s != nil {\ngenBuilder = genBuilder.%s(%q, fmt.Sprint(*%s))\n}\n", p.name, call, p.key, p.name)
+		default:
+			fmt.Fprintf(out, "genBuilder = genBuilder.%s(%q, fmt.Sprint(%s))\n", call, p.key, p.name)
+		}
+	}
+
+	switch {
+	case m.resultType == "":
+		fmt.Fprintf(out, "return genService.execute(%s, genBuilder, nil)\n", ctx)
+	case m.pointer:
+		fmt.Fprintf(out, "var genResult %s\nif genError := genService.execute(%s, genBuilder, &genResult); genError != nil {\nreturn nil, genError\n}\nreturn &genResult, nil\n", m.resultType, ctx)
+	default:
+		fmt.Fprintf(out, "var genResult %s\ngenError := genService.execute(%s, genBuilder, &genResult)\nreturn genResult, genError\n", m.resultType, ctx)
+	}
+	fmt.Fprintln(out, "}")
+}
+
+func unexported(name string) string {
+	runes := []rune(name)
+	for index := range runes {
+		if index > 0 && index+1 < len(runes) && unicode.IsLower(runes[index+1]) {
+			break
+		}
+		runes[index] = unicode.ToLower(runes[index])
+	}
+	return string(runes)
+}
+
+// outputName turns UserService into user_service_client.gen.go.
+func outputName(typeName string) string {
+	var builder strings.Builder
+	for index, r := range typeName {
+		if unicode.IsUpper(r) && index > 0 {
+			builder.WriteByte('_')
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String() + "_client.gen.go"
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// The users golden file is a real package, so go build and its own tests
+// also check that the generated code compiles and works.
+func TestGenerate_Golden(t *testing.T) {
+	dir := filepath.Join("internal", "users")
+	svc, err := parseService(dir, "UserService")
+	assert.NoError(t, err)
+
+	code, err := generate(svc)
+	assert.NoError(t, err)
+
+	golden := filepath.Join(dir, outputName("UserService"))
+	if *update {
+		assert.NoError(t, os.WriteFile(golden, code, 0o644))
+	}
+	expected, err := os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(code))
+}
+
+func TestParseService_Errors(t *testing.T) {
+	tests := map[string]string{
+		`// @GET /users/{id}
+	Get(ctx context.Context) *httperror.HTTPError`: "Service.Get: path parameter {id} is not bound to a method parameter",
+		`// @GET /users
+	Get(ctx context.Context, page int) *httperror.HTTPError`: "Service.Get: parameter page is not bound, annotate it with @Path, @Query, @Header or @Body",
+		`// @GET /users
+	// @Cookie session
+	Get(ctx context.Context, session string) *httperror.HTTPError`: "Service.Get: unknown annotation @Cookie",
+		`Get(ctx context.Context) *httperror.HTTPError`: "Service.Get: missing an HTTP method annotation such as // @GET /path",
+		`// @GET /users
+	// @Body user
+	Get(ctx context.Context, user string) *httperror.HTTPError`: "Service.Get: @GET requests cannot have a body",
+		`// @GET /users
+	Get(page int) *httperror.HTTPError`: "Service.Get: the first parameter must be a context.Context",
+		`// @GET /users
+	// @Query page http
+	Get(ctx context.Context, http int) *httperror.HTTPError`: "Service.Get: parameter http shadows a package of the generated code",
+		`// @GET /users
+	Get(ctx context.Context) error`: "Service.Get: results must be (T, *httperror.HTTPError) or *httperror.HTTPError",
+	}
+
+	for methods, message := range tests {
+		dir := t.TempDir()
+		source := "package service\n\nimport (\n\t\"context\"\n\n\t\"github.com/ttanik/http-client/httperror\"\n)\n\ntype Service interface {\n\t" + methods + "\n}\n"
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "service.go"), []byte(source), 0o644))
+
+		_, err := parseService(dir, "Service")
+		assert.EqualError(t, err, message)
+	}
+}
+
+func TestParseService_NotFound(t *testing.T) {
+	_, err := parseService(filepath.Join("internal", "users"), "Missing")
+
+	assert.EqualError(t, err, "interface Missing not found in internal/users")
+}
+
+func TestOutputName(t *testing.T) {
+	assert.Equal(t, "user_service_client.gen.go", outputName("UserService"))
+	assert.Equal(t, "userService", unexported("UserService"))
+	assert.Equal(t, "api", unexported("API"))
+	assert.Equal(t, "httpService", unexported("HTTPService"))
+}
@@ -0,0 +1,127 @@
+// Code generated by httpclient-iface. DO NOT EDIT.
+
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httperror"
+)
+
+type userServiceClient struct {
+	client  *httpclient.Client
+	decoder *httpdecoder.Decoder
+}
+
+// NewUserService implements UserService on top of client.
+func NewUserService(client *httpclient.Client) UserService {
+	return &userServiceClient{client: client, decoder: httpdecoder.NewHTTPDecoder()}
+}
+
+func (service *userServiceClient) execute(ctx context.Context, builder httpclient.HTTPRequestBuilder, result interface{}) *httperror.HTTPError {
+	request, httpError := builder.Build()
+	if httpError != nil {
+		return httpError
+	}
+	response, httpError := service.client.ExecuteRequest(request)
+	if httpError != nil {
+		return httpError
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return &httperror.HTTPError{
+			Status:  response.StatusCode,
+			Message: "unexpected response status",
+			Err:     service.decoder.DecodeErrorBody(ctx, response),
+			Time:    time.Now(),
+		}
+	}
+	if result == nil || response.StatusCode == http.StatusNoContent {
+		if response.Body != nil {
+			_ = response.Body.Close()
+		}
+		return nil
+	}
+	return service.decoder.DecodeResponseBody(ctx, response, result)
+}
+
+// GetUser ...
+func (genService *userServiceClient) GetUser(ctx context.Context, id int64) (*User, *httperror.HTTPError) {
+	genBuilder := genService.client.NewRequestBuilder(ctx).
+		WithMethod(http.MethodGet).
+		WithEndpoint("/users/{id}")
+	genBuilder = genBuilder.WithPathParam("id", fmt.Sprint(id))
+	var genResult User
+	if genError := genService.execute(ctx, genBuilder, &genResult); genError != nil {
+		return nil, genError
+	}
+	return &genResult, nil
+}
+
+// ListUsers ...
+func (genService *userServiceClient) ListUsers(ctx context.Context, page int, tags []string, prefix *string, tenant string) ([]User, *httperror.HTTPError) {
+	genBuilder := genService.client.NewRequestBuilder(ctx).
+		WithMethod(http.MethodGet).
+		WithEndpoint("/users")
+	genBuilder = genBuilder.WithQueryParam("page", fmt.Sprint(page))
+	for _, genValue := range tags {
+		genBuilder = genBuilder.WithQueryParam("tag", fmt.Sprint(genValue))
+	}
+	if prefix != nil {
+		genBuilder = genBuilder.WithQueryParam("name_prefix", fmt.Sprint(*prefix))
+	}
+	genBuilder = genBuilder.AddHeader("X-Tenant", fmt.Sprint(tenant))
+	var genResult []User
+	genError := genService.execute(ctx, genBuilder, &genResult)
+	return genResult, genError
+}
+
+// CreateUser ...
+func (genService *userServiceClient) CreateUser(ctx context.Context, user NewUser) (*User, *httperror.HTTPError) {
+	genBuilder := genService.client.NewRequestBuilder(ctx).
+		WithMethod(http.MethodPost).
+		WithEndpoint("/users")
+	genBuilder = genBuilder.WithBody(user)
+	var genResult User
+	if genError := genService.execute(ctx, genBuilder, &genResult); genError != nil {
+		return nil, genError
+	}
+	return &genResult, nil
+}
+
+// AddToTeam ...
+func (genService *userServiceClient) AddToTeam(ctx context.Context, team string, id int64) *httperror.HTTPError {
+	genBuilder := genService.client.NewRequestBuilder(ctx).
+		WithMethod(http.MethodPut).
+		WithEndpoint("/teams/{team}/users/{userID}")
+	genBuilder = genBuilder.WithPathParam("team", fmt.Sprint(team))
+	genBuilder = genBuilder.WithPathParam("userID", fmt.Sprint(id))
+	return genService.execute(ctx, genBuilder, nil)
+}
+
+// Count ...
+func (genService *userServiceClient) Count(ctx context.Context) (int, *httperror.HTTPError) {
+	genBuilder := genService.client.NewRequestBuilder(ctx).
+		WithMethod(http.MethodGet).
+		WithEndpoint("/users/count")
+	var genResult int
+	genError := genService.execute(ctx, genBuilder, &genResult)
+	return genResult, genError
+}
+
+// Search ...
+func (genService *userServiceClient) Search(ctx context.Context, result string, service string) ([]User, *httperror.HTTPError) {
+	genBuilder := genService.client.NewRequestBuilder(ctx).
+		WithMethod(http.MethodGet).
+		WithEndpoint("/users/search")
+	genBuilder = genBuilder.WithQueryParam("q", fmt.Sprint(result))
+	genBuilder = genBuilder.AddHeader("X-Service", fmt.Sprint(service))
+	var genResult []User
+	genError := genService.execute(ctx, genBuilder, &genResult)
+	return genResult, genError
+}
@@ -0,0 +1,51 @@
+// Package users is an example of a client generated by httpclient-iface,
+// also used as its golden file.
+package users
+
+import (
+	"context"
+
+	"github.com/ttanik/http-client/httperror"
+)
+
+// User ...
+type User struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewUser ...
+type NewUser struct {
+	Name string `json:"name"`
+}
+
+//go:generate go run ../.. -type UserService
+
+// UserService ...
+type UserService interface {
+	// @GET /users/{id}
+	GetUser(ctx context.Context, id int64) (*User, *httperror.HTTPError)
+
+	// @GET /users
+	// @Query page
+	// @Query tag tags
+	// @Query name_prefix prefix
+	// @Header X-Tenant tenant
+	ListUsers(ctx context.Context, page int, tags []string, prefix *string, tenant string) ([]User, *httperror.HTTPError)
+
+	// @POST /users
+	// @Body user
+	CreateUser(ctx context.Context, user NewUser) (*User, *httperror.HTTPError)
+
+	// @PUT /teams/{team}/users/{userID}
+	// @Path userID id
+	AddToTeam(ctx context.Context, team string, id int64) *httperror.HTTPError
+
+	// @GET /users/count
+	Count(ctx context.Context) (int, *httperror.HTTPError)
+
+	// @GET /users/search
+	// @Query q result
+	// @Header X-Service service
+	Search(ctx context.Context, result string, service string) ([]User, *httperror.HTTPError)
+}
@@ -0,0 +1,127 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httperror"
+)
+
+func newTestService(t *testing.T, handler http.HandlerFunc) UserService {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewUserService(httpclient.NewHTTPClient(httpclient.WithBaseURL(server.URL)))
+}
+
+func TestUserService_GetUser(t *testing.T) {
+	service := newTestService(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodGet, request.Method)
+		assert.Equal(t, "/users/42", request.URL.Path)
+		_, _ = writer.Write([]byte(`{"id":42,"name":"ada"}`))
+	})
+
+	user, httpError := service.GetUser(context.Background(), 42)
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, &User{ID: 42, Name: "ada"}, user)
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+	service := newTestService(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/users", request.URL.Path)
+		assert.Equal(t, "name_prefix=a&page=2&tag=admin&tag=ops", request.URL.Query().Encode())
+		assert.Equal(t, "acme", request.Header.Get("X-Tenant"))
+		_, _ = writer.Write([]byte(`[{"id":1,"name":"ada"}]`))
+	})
+
+	prefix := "a"
+	users, httpError := service.ListUsers(context.Background(), 2, []string{"admin", "ops"}, &prefix, "acme")
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, []User{{ID: 1, Name: "ada"}}, users)
+}
+
+func TestUserService_ListUsers_OmitsNilQuery(t *testing.T) {
+	service := newTestService(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "page=1", request.URL.RawQuery)
+		_, _ = writer.Write([]byte(`[]`))
+	})
+
+	users, httpError := service.ListUsers(context.Background(), 1, nil, nil, "acme")
+
+	assert.Nil(t, httpError)
+	assert.Empty(t, users)
+}
+
+func TestUserService_CreateUser(t *testing.T) {
+	service := newTestService(t, func(writer http.ResponseWriter, request *http.Request) {
+		var user NewUser
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&user))
+		assert.Equal(t, http.MethodPost, request.Method)
+		writer.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(writer).Encode(User{ID: 7, Name: user.Name})
+	})
+
+	user, httpError := service.CreateUser(context.Background(), NewUser{Name: "grace"})
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, &User{ID: 7, Name: "grace"}, user)
+}
+
+func TestUserService_AddToTeam(t *testing.T) {
+	service := newTestService(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodPut, request.Method)
+		assert.Equal(t, "/teams/red%20team/users/9", request.URL.EscapedPath())
+		writer.WriteHeader(http.StatusNoContent)
+	})
+
+	httpError := service.AddToTeam(context.Background(), "red team", 9)
+
+	assert.Nil(t, httpError)
+}
+
+func TestUserService_Count(t *testing.T) {
+	service := newTestService(t, func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`12`))
+	})
+
+	count, httpError := service.Count(context.Background())
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, 12, count)
+}
+
+func TestUserService_Search(t *testing.T) {
+	service := newTestService(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "ada", request.URL.Query().Get("q"))
+		assert.Equal(t, "billing", request.Header.Get("X-Service"))
+		_, _ = writer.Write([]byte(`[{"id":1,"name":"ada"}]`))
+	})
+
+	users, httpError := service.Search(context.Background(), "ada", "billing")
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, []User{{ID: 1, Name: "ada"}}, users)
+}
+
+func TestUserService_ErrorStatus(t *testing.T) {
+	service := newTestService(t, func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte(`{"message":"user not found"}`))
+	})
+
+	user, httpError := service.GetUser(context.Background(), 1)
+
+	assert.Nil(t, user)
+	assert.Equal(t, http.StatusNotFound, httpError.Status)
+	assert.False(t, httpError.Time.IsZero())
+	var body *httperror.HTTPError
+	assert.ErrorAs(t, httpError.Err, &body)
+	assert.Equal(t, http.StatusNotFound, body.Status)
+	assert.Equal(t, "user not found", body.Message)
+}
@@ -0,0 +1,62 @@
+// Command httpclient-iface implements annotated interfaces on top of
+// httpclient.Client, in the spirit of Retrofit:
+//
+//	//go:generate go run github.com/ttanik/http-client/cmd/httpclient-iface -type UserService
+//	type UserService interface {
+//		// @GET /users/{id}
+//		GetUser(ctx context.Context, id int64) (*User, *httperror.HTTPError)
+//
+//		// @GET /users
+//		// @Query page
+//		// @Header X-Tenant tenant
+//		ListUsers(ctx context.Context, page int, tenant string) ([]User, *httperror.HTTPError)
+//
+//		// @POST /users
+//		// @Body user
+//		CreateUser(ctx context.Context, user NewUser) (*User, *httperror.HTTPError)
+//	}
+//
+// Path placeholders bind to the parameter of the same name unless a
+// "@Path name param" annotation says otherwise. Every other parameter but the
+// context needs an @Query, @Header or @Body annotation; a "@Query name"
+// annotation with one argument binds the parameter called name.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	typeName := flag.String("type", "", "annotated interface to implement")
+	dir := flag.String("dir", ".", "package directory")
+	outPath := flag.String("out", "", "output file, defaults to <type>_client.gen.go in dir")
+	flag.Parse()
+
+	if err := run(*typeName, *dir, *outPath); err != nil {
+		fmt.Fprintln(os.Stderr, "httpclient-iface:", err)
+		os.Exit(1)
+	}
+}
+
+func run(typeName string, dir string, outPath string) error {
+	if typeName == "" {
+		return fmt.Errorf("-type is required")
+	}
+
+	svc, err := parseService(dir, typeName)
+	if err != nil {
+		return err
+	}
+	code, err := generate(svc)
+	if err != nil {
+		return err
+	}
+
+	if outPath == "" {
+		outPath = filepath.Join(dir, outputName(typeName))
+	}
+	return os.WriteFile(outPath, code, 0o644)
+}
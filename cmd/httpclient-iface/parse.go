@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+var httpMethods = map[string]string{
+	"GET":    "http.MethodGet",
+	"HEAD":   "http.MethodHead",
+	"POST":   "http.MethodPost",
+	"PUT":    "http.MethodPut",
+	"PATCH":  "http.MethodPatch",
+	"DELETE": "http.MethodDelete",
+}
+
+// service is an annotated interface.
+type service struct {
+	packageName string
+	name        string
+	imports     []string
+	methods     []*method
+}
+
+type method struct {
+	name       string
+	httpMethod string
+	path       string
+	params     []*methodParam
+	signature  string
+	resultType string
+	pointer    bool
+}
+
+type methodParam struct {
+	name     string
+	goType   string
+	role     string
+	key      string
+	slice    bool
+	optional bool
+}
+
+// parseService finds the interface typeName in the Go files of dir.
+func parseService(dir string, typeName string) (*service, error) {
+	fileSet := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fileSet, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, declaration := range file.Decls {
+			general, ok := declaration.(*ast.GenDecl)
+			if !ok || general.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range general.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if typeSpec.Name.Name != typeName {
+					continue
+				}
+				iface, ok := typeSpec.Type.(*ast.InterfaceType)
+				if !ok {
+					return nil, fmt.Errorf("%s is not an interface", typeName)
+				}
+				return newService(fileSet, file, typeName, iface)
+			}
+		}
+	}
+	return nil, fmt.Errorf("interface %s not found in %s", typeName, dir)
+}
+
+func newService(fileSet *token.FileSet, file *ast.File, typeName string, iface *ast.InterfaceType) (*service, error) {
+	svc := &service{packageName: file.Name.Name, name: typeName}
+	used := map[string]bool{}
+
+	for _, field := range iface.Methods.List {
+		function, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded interfaces are not supported", typeName)
+		}
+
+		ast.Inspect(function, func(node ast.Node) bool {
+			if selector, ok := node.(*ast.SelectorExpr); ok {
+				if ident, ok := selector.X.(*ast.Ident); ok {
+					used[ident.Name] = true
+				}
+			}
+			return true
+		})
+
+		m, err := newMethod(fileSet, field.Names[0].Name, field.Doc, function)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", typeName, field.Names[0].Name, err)
+		}
+		svc.methods = append(svc.methods, m)
+	}
+
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := filepath.Base(path)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if !used[name] {
+			continue
+		}
+		if spec.Name != nil {
+			svc.imports = append(svc.imports, spec.Name.Name+" "+spec.Path.Value)
+		} else {
+			svc.imports = append(svc.imports, spec.Path.Value)
+		}
+	}
+	sort.Strings(svc.imports)
+	return svc, nil
+}
+
+// shadowedPackages are used by the generated methods, so parameters cannot
+// take their names.
+var shadowedPackages = map[string]bool{"fmt": true, "http": true}
+
+func newMethod(fileSet *token.FileSet, name string, doc *ast.CommentGroup, function *ast.FuncType) (*method, error) {
+	m := &method{name: name}
+	expression := func(node ast.Node) string {
+		var buffer bytes.Buffer
+		_ = printer.Fprint(&buffer, fileSet, node)
+		return buffer.String()
+	}
+
+	var params []*methodParam
+	var arguments []string
+	for _, field := range function.Params.List {
+		goType := expression(field.Type)
+		if len(field.Names) == 0 {
+			return nil, fmt.Errorf("parameters must be named")
+		}
+		for _, ident := range field.Names {
+			if shadowedPackages[ident.Name] {
+				return nil, fmt.Errorf("parameter %s shadows a package of the generated code", ident.Name)
+			}
+			params = append(params, &methodParam{
+				name:     ident.Name,
+				goType:   goType,
+				slice:    strings.HasPrefix(goType, "[]"),
+				optional: strings.HasPrefix(goType, "*"),
+			})
+			arguments = append(arguments, ident.Name+" "+goType)
+		}
+	}
+	if len(params) == 0 || params[0].goType != "context.Context" {
+		return nil, fmt.Errorf("the first parameter must be a context.Context")
+	}
+	params[0].role = "context"
+
+	var results []string
+	if function.Results != nil {
+		for _, field := range function.Results.List {
+			if len(field.Names) > 0 {
+				return nil, fmt.Errorf("results must not be named")
+			}
+			results = append(results, expression(field.Type))
+		}
+	}
+	if len(results) == 0 || len(results) > 2 || results[len(results)-1] != "*httperror.HTTPError" {
+		return nil, fmt.Errorf("results must be (T, *httperror.HTTPError) or *httperror.HTTPError")
+	}
+	if len(results) == 2 {
+		m.resultType = results[0]
+		if strings.HasPrefix(m.resultType, "*") {
+			m.pointer = true
+			m.resultType = m.resultType[1:]
+		}
+	}
+
+	returns := results[0]
+	if len(results) == 2 {
+		returns = "(" + strings.Join(results, ", ") + ")"
+	}
+	m.signature = fmt.Sprintf("%s(%s) %s", name, strings.Join(arguments, ", "), returns)
+
+	byName := map[string]*methodParam{}
+	for _, p := range params {
+		byName[p.name] = p
+	}
+	bind := func(paramName string, role string, key string) error {
+		p, ok := byName[paramName]
+		if !ok {
+			return fmt.Errorf("@%s refers to unknown parameter %s", role, paramName)
+		}
+		if p.role != "" {
+			return fmt.Errorf("parameter %s is bound twice", paramName)
+		}
+		p.role, p.key = role, key
+		return nil
+	}
+
+	if doc != nil {
+		for _, comment := range doc.List {
+			line := strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(comment.Text, "//"), " "))
+			if !strings.HasPrefix(line, "@") {
+				continue
+			}
+			fields := strings.Fields(line)
+			annotation := strings.TrimPrefix(fields[0], "@")
+			args := fields[1:]
+
+			if _, ok := httpMethods[strings.ToUpper(annotation)]; ok {
+				if m.httpMethod != "" {
+					return nil, fmt.Errorf("more than one HTTP method annotation")
+				}
+				if len(args) != 1 {
+					return nil, fmt.Errorf("@%s takes a path", annotation)
+				}
+				m.httpMethod, m.path = strings.ToUpper(annotation), args[0]
+				continue
+			}
+
+			var err error
+			switch annotation {
+			case "Path", "Query", "Header":
+				if len(args) < 1 || len(args) > 2 {
+					return nil, fmt.Errorf("@%s takes a name and an optional parameter", annotation)
+				}
+				paramName := args[len(args)-1]
+				err = bind(paramName, strings.ToLower(annotation), args[0])
+			case "Body":
+				if len(args) != 1 {
+					return nil, fmt.Errorf("@Body takes a parameter")
+				}
+				err = bind(args[0], "body", "")
+			default:
+				err = fmt.Errorf("unknown annotation @%s", annotation)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if m.httpMethod == "" {
+		return nil, fmt.Errorf("missing an HTTP method annotation such as // @GET /path")
+	}
+
+	// Path placeholders bind to parameters of the same name by default.
+	for _, match := range pathParamPattern.FindAllStringSubmatch(m.path, -1) {
+		placeholder := match[1]
+		bound := false
+		for _, p := range params {
+			if p.role == "path" && p.key == placeholder {
+				bound = true
+			}
+		}
+		if bound {
+			continue
+		}
+		p, ok := byName[placeholder]
+		if !ok || p.role != "" {
+			return nil, fmt.Errorf("path parameter {%s} is not bound to a method parameter", placeholder)
+		}
+		p.role, p.key = "path", placeholder
+	}
+
+	for _, p := range params {
+		switch {
+		case p.role == "":
+			return nil, fmt.Errorf("parameter %s is not bound, annotate it with @Path, @Query, @Header or @Body", p.name)
+		case p.role == "path" && !strings.Contains(m.path, "{"+p.key+"}"):
+			return nil, fmt.Errorf("path parameter {%s} does not appear in %s", p.key, m.path)
+		}
+	}
+
+	if m.httpMethod == http.MethodGet || m.httpMethod == http.MethodHead {
+		for _, p := range params {
+			if p.role == "body" {
+				return nil, fmt.Errorf("@%s requests cannot have a body", m.httpMethod)
+			}
+		}
+	}
+
+	m.params = params
+	return m, nil
+}
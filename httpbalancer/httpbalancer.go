@@ -0,0 +1,304 @@
+package httpbalancer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ttanik/http-client/httperror"
+)
+
+const (
+	defaultFailureThreshold    = 3
+	defaultHealthCheckPath     = "/health"
+	defaultHealthCheckInterval = 10 * time.Second
+)
+
+// Requester ...
+type Requester interface {
+	ExecuteRequest(request *http.Request) (*http.Response, *httperror.HTTPError)
+}
+
+// Configs ...
+type Configs struct {
+	// Picker defaults to RoundRobin.
+	Picker Picker
+	// FailureThreshold is the number of consecutive failures after which an
+	// endpoint is ejected.
+	FailureThreshold int
+	// HealthCheckPath is requested on ejected endpoints; a 2xx or 3xx answer
+	// brings the endpoint back.
+	HealthCheckPath     string
+	HealthCheckInterval time.Duration
+	// RefreshInterval re-runs the Resolver periodically. Zero disables it.
+	RefreshInterval time.Duration
+}
+
+// Endpoint ...
+type Endpoint struct {
+	baseURL  *url.URL
+	inFlight atomic.Int64
+	failures atomic.Int64
+	ejected  atomic.Bool
+}
+
+// URL ...
+func (endpoint *Endpoint) URL() *url.URL {
+	baseURL := *endpoint.baseURL
+	return &baseURL
+}
+
+// InFlight ...
+func (endpoint *Endpoint) InFlight() int64 {
+	return endpoint.inFlight.Load()
+}
+
+// Healthy ...
+func (endpoint *Endpoint) Healthy() bool {
+	return !endpoint.ejected.Load()
+}
+
+func (endpoint *Endpoint) resolve(target *url.URL) *url.URL {
+	resolved := *target
+	resolved.Scheme = endpoint.baseURL.Scheme
+	resolved.Host = endpoint.baseURL.Host
+	resolved.User = endpoint.baseURL.User
+	resolved.Path = strings.TrimSuffix(endpoint.baseURL.Path, "/") + "/" + strings.TrimPrefix(target.Path, "/")
+	resolved.RawPath = ""
+	return &resolved
+}
+
+// NewBalancer ...
+func NewBalancer(requester Requester, resolver Resolver, configs Configs) *Balancer {
+	if configs.Picker == nil {
+		configs.Picker = RoundRobin()
+	}
+	if configs.FailureThreshold <= 0 {
+		configs.FailureThreshold = defaultFailureThreshold
+	}
+	if configs.HealthCheckPath == "" {
+		configs.HealthCheckPath = defaultHealthCheckPath
+	}
+	if configs.HealthCheckInterval <= 0 {
+		configs.HealthCheckInterval = defaultHealthCheckInterval
+	}
+
+	return &Balancer{
+		requester: requester,
+		resolver:  resolver,
+		configs:   configs,
+	}
+}
+
+// Balancer spreads requests with a relative URL across the resolved
+// endpoints. Requests with an absolute URL are passed through untouched.
+type Balancer struct {
+	requester Requester
+	resolver  Resolver
+	configs   Configs
+
+	mutex     sync.RWMutex
+	endpoints []*Endpoint
+}
+
+// Start resolves the endpoints and runs health checks and resolver refreshes
+// until ctx is done.
+func (balancer *Balancer) Start(ctx context.Context) *httperror.HTTPError {
+	if err := balancer.Refresh(ctx); err != nil {
+		return err
+	}
+
+	go balancer.run(ctx)
+	return nil
+}
+
+// Refresh re-runs the Resolver. Endpoints that are still resolved keep their
+// health state.
+func (balancer *Balancer) Refresh(ctx context.Context) *httperror.HTTPError {
+	baseURLs, err := balancer.resolver.Resolve(ctx)
+	if err != nil {
+		return &httperror.HTTPError{
+			Status:  http.StatusServiceUnavailable,
+			Message: "error resolving endpoints",
+			Err:     err,
+			Time:    time.Now(),
+		}
+	}
+
+	balancer.mutex.RLock()
+	existing := make(map[string]*Endpoint, len(balancer.endpoints))
+	for _, endpoint := range balancer.endpoints {
+		existing[endpoint.baseURL.String()] = endpoint
+	}
+	balancer.mutex.RUnlock()
+
+	endpoints := make([]*Endpoint, 0, len(baseURLs))
+	for _, baseURL := range baseURLs {
+		parsedURL, err := url.Parse(baseURL)
+		if err != nil || parsedURL.Host == "" {
+			return &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "invalid endpoint url " + baseURL,
+				Err:     err,
+				Time:    time.Now(),
+			}
+		}
+
+		if endpoint, ok := existing[parsedURL.String()]; ok {
+			endpoints = append(endpoints, endpoint)
+			continue
+		}
+		endpoints = append(endpoints, &Endpoint{baseURL: parsedURL})
+	}
+
+	balancer.mutex.Lock()
+	balancer.endpoints = endpoints
+	balancer.mutex.Unlock()
+
+	return nil
+}
+
+// Endpoints ...
+func (balancer *Balancer) Endpoints() []*Endpoint {
+	balancer.mutex.RLock()
+	defer balancer.mutex.RUnlock()
+
+	endpoints := make([]*Endpoint, len(balancer.endpoints))
+	copy(endpoints, balancer.endpoints)
+	return endpoints
+}
+
+// ExecuteRequest ...
+func (balancer *Balancer) ExecuteRequest(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	if request.URL != nil && request.URL.Host != "" {
+		return balancer.requester.ExecuteRequest(request)
+	}
+
+	endpoint := balancer.pick()
+	if endpoint == nil {
+		return nil, &httperror.HTTPError{
+			Status:  http.StatusServiceUnavailable,
+			Message: "no endpoints available",
+			Time:    time.Now(),
+		}
+	}
+
+	endpointRequest := request.Clone(request.Context())
+	endpointRequest.URL = endpoint.resolve(request.URL)
+	endpointRequest.Host = ""
+
+	endpoint.inFlight.Add(1)
+	response, httpError := balancer.requester.ExecuteRequest(endpointRequest)
+	endpoint.inFlight.Add(-1)
+
+	if request.Context().Err() == nil {
+		balancer.report(endpoint, isFailure(response, httpError))
+	}
+
+	return response, httpError
+}
+
+func (balancer *Balancer) pick() *Endpoint {
+	balancer.mutex.RLock()
+	defer balancer.mutex.RUnlock()
+
+	if len(balancer.endpoints) == 0 {
+		return nil
+	}
+
+	healthy := make([]*Endpoint, 0, len(balancer.endpoints))
+	for _, endpoint := range balancer.endpoints {
+		if endpoint.Healthy() {
+			healthy = append(healthy, endpoint)
+		}
+	}
+
+	// With every endpoint ejected it is better to keep trying all of them
+	// than to fail every request.
+	if len(healthy) == 0 {
+		return balancer.configs.Picker.Pick(balancer.endpoints)
+	}
+
+	return balancer.configs.Picker.Pick(healthy)
+}
+
+func (balancer *Balancer) report(endpoint *Endpoint, failed bool) {
+	if !failed {
+		endpoint.failures.Store(0)
+		return
+	}
+
+	if endpoint.failures.Add(1) >= int64(balancer.configs.FailureThreshold) {
+		endpoint.ejected.Store(true)
+	}
+}
+
+func (balancer *Balancer) run(ctx context.Context) {
+	healthCheck := time.NewTicker(balancer.configs.HealthCheckInterval)
+	defer healthCheck.Stop()
+
+	var refresh <-chan time.Time
+	if balancer.configs.RefreshInterval > 0 {
+		refreshTicker := time.NewTicker(balancer.configs.RefreshInterval)
+		defer refreshTicker.Stop()
+		refresh = refreshTicker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-healthCheck.C:
+			balancer.checkHealth(ctx)
+		case <-refresh:
+			_ = balancer.Refresh(ctx)
+		}
+	}
+}
+
+func (balancer *Balancer) checkHealth(ctx context.Context) {
+	for _, endpoint := range balancer.Endpoints() {
+		if endpoint.Healthy() {
+			continue
+		}
+
+		if balancer.probe(ctx, endpoint) {
+			endpoint.failures.Store(0)
+			endpoint.ejected.Store(false)
+		}
+	}
+}
+
+func (balancer *Balancer) probe(ctx context.Context, endpoint *Endpoint) bool {
+	ctx, cancel := context.WithTimeout(ctx, balancer.configs.HealthCheckInterval)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.resolve(&url.URL{Path: balancer.configs.HealthCheckPath}).String(), nil)
+	if err != nil {
+		return false
+	}
+
+	response, httpError := balancer.requester.ExecuteRequest(request)
+	if httpError != nil {
+		return false
+	}
+	if response.Body != nil {
+		_, _ = io.Copy(io.Discard, response.Body)
+		_ = response.Body.Close()
+	}
+
+	return response.StatusCode < http.StatusBadRequest
+}
+
+func isFailure(response *http.Response, httpError *httperror.HTTPError) bool {
+	if httpError != nil {
+		return httpError.Status >= http.StatusInternalServerError || httpError.Status == http.StatusFailedDependency
+	}
+
+	return response != nil && response.StatusCode >= http.StatusInternalServerError
+}
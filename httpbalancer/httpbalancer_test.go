@@ -0,0 +1,144 @@
+package httpbalancer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httperror"
+)
+
+type fakeRequester struct {
+	mutex    sync.Mutex
+	hosts    []string
+	statuses map[string]int
+}
+
+func (requester *fakeRequester) ExecuteRequest(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	requester.mutex.Lock()
+	defer requester.mutex.Unlock()
+
+	requester.hosts = append(requester.hosts, request.URL.Host+request.URL.Path)
+	if status := requester.statuses[request.URL.Host]; status != 0 {
+		return nil, &httperror.HTTPError{Status: status, Message: "dependency failed"}
+	}
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func (requester *fakeRequester) setStatus(host string, status int) {
+	requester.mutex.Lock()
+	defer requester.mutex.Unlock()
+	requester.statuses[host] = status
+}
+
+func newTestBalancer(t *testing.T, configs Configs) (*Balancer, *fakeRequester) {
+	requester := &fakeRequester{statuses: map[string]int{}}
+	balancer := NewBalancer(requester, NewStaticResolver("http://a:80/api", "http://b:80"), configs)
+	assert.Nil(t, balancer.Refresh(context.Background()))
+	return balancer, requester
+}
+
+func newRelativeRequest(path string) *http.Request {
+	request, _ := http.NewRequest(http.MethodGet, path, nil)
+	return request
+}
+
+func TestBalancer_ExecuteRequest_RoundRobin(t *testing.T) {
+	balancer, requester := newTestBalancer(t, Configs{})
+
+	for i := 0; i < 4; i++ {
+		_, httpError := balancer.ExecuteRequest(newRelativeRequest("/users/1"))
+		assert.Nil(t, httpError)
+	}
+
+	assert.Equal(t, []string{"a:80/api/users/1", "b:80/users/1", "a:80/api/users/1", "b:80/users/1"}, requester.hosts)
+}
+
+func TestBalancer_ExecuteRequest_AbsoluteURL(t *testing.T) {
+	balancer, requester := newTestBalancer(t, Configs{})
+
+	_, httpError := balancer.ExecuteRequest(newRelativeRequest("http://c/users"))
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, []string{"c/users"}, requester.hosts)
+}
+
+func TestBalancer_ExecuteRequest_NoEndpoints(t *testing.T) {
+	balancer := NewBalancer(&fakeRequester{}, NewStaticResolver(), Configs{})
+
+	response, httpError := balancer.ExecuteRequest(newRelativeRequest("/users"))
+
+	assert.Nil(t, response)
+	assert.Equal(t, http.StatusServiceUnavailable, httpError.Status)
+}
+
+func TestBalancer_ExecuteRequest_EjectsAndRecovers(t *testing.T) {
+	balancer, requester := newTestBalancer(t, Configs{FailureThreshold: 2})
+	requester.setStatus("a:80", http.StatusFailedDependency)
+
+	for i := 0; i < 4; i++ {
+		_, _ = balancer.ExecuteRequest(newRelativeRequest("/users"))
+	}
+	endpoints := balancer.Endpoints()
+	assert.False(t, endpoints[0].Healthy())
+	assert.True(t, endpoints[1].Healthy())
+
+	balancer.checkHealth(context.Background())
+	assert.False(t, endpoints[0].Healthy())
+
+	requester.setStatus("a:80", 0)
+	balancer.checkHealth(context.Background())
+	assert.True(t, endpoints[0].Healthy())
+	assert.Contains(t, requester.hosts, "a:80/api/health")
+}
+
+func TestBalancer_ExecuteRequest_ClientErrorsDoNotEject(t *testing.T) {
+	balancer, requester := newTestBalancer(t, Configs{FailureThreshold: 1})
+	requester.setStatus("a:80", http.StatusNotFound)
+
+	_, _ = balancer.ExecuteRequest(newRelativeRequest("/users"))
+
+	assert.True(t, balancer.Endpoints()[0].Healthy())
+}
+
+func TestBalancer_Start_RunsHealthChecks(t *testing.T) {
+	balancer, requester := newTestBalancer(t, Configs{FailureThreshold: 1, HealthCheckInterval: 5 * time.Millisecond})
+	requester.setStatus("b:80", http.StatusBadGateway)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	assert.Nil(t, balancer.Start(ctx))
+
+	_, _ = balancer.ExecuteRequest(newRelativeRequest("/users"))
+	_, _ = balancer.ExecuteRequest(newRelativeRequest("/users"))
+	assert.False(t, balancer.Endpoints()[1].Healthy())
+
+	requester.setStatus("b:80", 0)
+	assert.Eventually(t, func() bool { return balancer.Endpoints()[1].Healthy() }, time.Second, 5*time.Millisecond)
+}
+
+func TestBalancer_Refresh_KeepsEndpointState(t *testing.T) {
+	balancer, _ := newTestBalancer(t, Configs{})
+	balancer.Endpoints()[1].ejected.Store(true)
+
+	assert.Nil(t, balancer.Refresh(context.Background()))
+
+	assert.False(t, balancer.Endpoints()[1].Healthy())
+}
+
+func TestPickers(t *testing.T) {
+	endpoints := []*Endpoint{
+		{baseURL: &url.URL{Host: "a"}},
+		{baseURL: &url.URL{Host: "b"}},
+	}
+	endpoints[0].inFlight.Store(5)
+
+	assert.Equal(t, endpoints[1], LeastInFlight().Pick(endpoints))
+	assert.Equal(t, endpoints[1], PowerOfTwoChoices().Pick(endpoints))
+	assert.Contains(t, endpoints, Random().Pick(endpoints))
+	assert.Equal(t, endpoints[0], PowerOfTwoChoices().Pick(endpoints[:1]))
+}
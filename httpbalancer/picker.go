@@ -0,0 +1,99 @@
+package httpbalancer
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Picker chooses the endpoint for the next request. endpoints is never empty.
+type Picker interface {
+	Pick(endpoints []*Endpoint) *Endpoint
+}
+
+// RoundRobin ...
+func RoundRobin() Picker {
+	return &roundRobinPicker{}
+}
+
+type roundRobinPicker struct {
+	next atomic.Uint64
+}
+
+func (picker *roundRobinPicker) Pick(endpoints []*Endpoint) *Endpoint {
+	index := picker.next.Add(1) - 1
+	return endpoints[index%uint64(len(endpoints))]
+}
+
+// Random ...
+func Random() Picker {
+	return &randomPicker{random: newRandom()}
+}
+
+type randomPicker struct {
+	random *lockedRandom
+}
+
+func (picker *randomPicker) Pick(endpoints []*Endpoint) *Endpoint {
+	return endpoints[picker.random.Intn(len(endpoints))]
+}
+
+// LeastInFlight ...
+func LeastInFlight() Picker {
+	return &leastInFlightPicker{}
+}
+
+type leastInFlightPicker struct{}
+
+func (picker *leastInFlightPicker) Pick(endpoints []*Endpoint) *Endpoint {
+	best := endpoints[0]
+	for _, endpoint := range endpoints[1:] {
+		if endpoint.InFlight() < best.InFlight() {
+			best = endpoint
+		}
+	}
+	return best
+}
+
+// PowerOfTwoChoices picks two endpoints at random and keeps the one with
+// fewer requests in flight.
+func PowerOfTwoChoices() Picker {
+	return &powerOfTwoPicker{random: newRandom()}
+}
+
+type powerOfTwoPicker struct {
+	random *lockedRandom
+}
+
+func (picker *powerOfTwoPicker) Pick(endpoints []*Endpoint) *Endpoint {
+	if len(endpoints) == 1 {
+		return endpoints[0]
+	}
+
+	first := picker.random.Intn(len(endpoints))
+	second := picker.random.Intn(len(endpoints) - 1)
+	if second >= first {
+		second++
+	}
+
+	if endpoints[second].InFlight() < endpoints[first].InFlight() {
+		return endpoints[second]
+	}
+	return endpoints[first]
+}
+
+type lockedRandom struct {
+	mutex  sync.Mutex
+	random *rand.Rand
+}
+
+func newRandom() *lockedRandom {
+	return &lockedRandom{random: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+func (random *lockedRandom) Intn(n int) int {
+	random.mutex.Lock()
+	defer random.mutex.Unlock()
+	return random.random.Intn(n)
+}
@@ -0,0 +1,100 @@
+package httpbalancer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// Resolver ...
+type Resolver interface {
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// NewStaticResolver ...
+func NewStaticResolver(baseURLs ...string) *StaticResolver {
+	return &StaticResolver{baseURLs: baseURLs}
+}
+
+// StaticResolver resolves to a fixed list of base URLs.
+type StaticResolver struct {
+	baseURLs []string
+}
+
+// Resolve ...
+func (resolver *StaticResolver) Resolve(ctx context.Context) ([]string, error) {
+	baseURLs := make([]string, len(resolver.baseURLs))
+	copy(baseURLs, resolver.baseURLs)
+	return baseURLs, nil
+}
+
+// NewSRVResolver ...
+func NewSRVResolver(scheme, service, proto, name string) *SRVResolver {
+	return &SRVResolver{
+		Scheme:   scheme,
+		Service:  service,
+		Proto:    proto,
+		Name:     name,
+		Resolver: net.DefaultResolver,
+	}
+}
+
+// SRVResolver resolves base URLs from DNS SRV records.
+type SRVResolver struct {
+	Scheme   string
+	Service  string
+	Proto    string
+	Name     string
+	Resolver *net.Resolver
+}
+
+// Resolve ...
+func (resolver *SRVResolver) Resolve(ctx context.Context) ([]string, error) {
+	_, records, err := resolver.Resolver.LookupSRV(ctx, resolver.Service, resolver.Proto, resolver.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURLs := make([]string, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		baseURLs = append(baseURLs, fmt.Sprintf("%s://%s", resolver.Scheme, net.JoinHostPort(host, fmt.Sprint(record.Port))))
+	}
+
+	return baseURLs, nil
+}
+
+// NewFileResolver ...
+func NewFileResolver(path string) *FileResolver {
+	return &FileResolver{path: path}
+}
+
+// FileResolver reads one base URL per line from a file. Blank lines and lines
+// starting with # are ignored.
+type FileResolver struct {
+	path string
+}
+
+// Resolve ...
+func (resolver *FileResolver) Resolve(ctx context.Context) ([]string, error) {
+	file, err := os.Open(resolver.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var baseURLs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		baseURLs = append(baseURLs, line)
+	}
+
+	return baseURLs, scanner.Err()
+}
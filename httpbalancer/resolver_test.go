@@ -0,0 +1,34 @@
+package httpbalancer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticResolver_Resolve(t *testing.T) {
+	baseURLs, err := NewStaticResolver("http://a", "http://b").Resolve(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://a", "http://b"}, baseURLs)
+}
+
+func TestFileResolver_Resolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints")
+	assert.NoError(t, os.WriteFile(path, []byte("# replicas\nhttp://a\n\n  http://b  \n"), 0o600))
+
+	baseURLs, err := NewFileResolver(path).Resolve(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://a", "http://b"}, baseURLs)
+}
+
+func TestFileResolver_Resolve_MissingFile(t *testing.T) {
+	baseURLs, err := NewFileResolver(filepath.Join(t.TempDir(), "missing")).Resolve(context.Background())
+
+	assert.Error(t, err)
+	assert.Nil(t, baseURLs)
+}
@@ -0,0 +1,135 @@
+package httpcache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	directives := cacheControl{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			name, argument, _ := strings.Cut(part, "=")
+			directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(argument), `"`)
+		}
+	}
+	return directives
+}
+
+func (directives cacheControl) has(name string) bool {
+	_, ok := directives[name]
+	return ok
+}
+
+func (directives cacheControl) seconds(name string) (time.Duration, bool) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// cacheableStatus lists the status codes that are cacheable by default,
+// RFC 7231 section 6.1.
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+func isStorable(request *http.Request, response *http.Response, shared bool) bool {
+	if request.Method != http.MethodGet || !cacheableStatus[response.StatusCode] {
+		return false
+	}
+
+	requestDirectives := parseCacheControl(request.Header)
+	responseDirectives := parseCacheControl(response.Header)
+	if requestDirectives.has("no-store") || responseDirectives.has("no-store") {
+		return false
+	}
+
+	if shared {
+		if responseDirectives.has("private") {
+			return false
+		}
+		if request.Header.Get("Authorization") != "" &&
+			!responseDirectives.has("public") && !responseDirectives.has("s-maxage") && !responseDirectives.has("must-revalidate") {
+			return false
+		}
+	}
+
+	if response.Header.Get("Vary") == "*" {
+		return false
+	}
+
+	_, hasLifetime := freshnessLifetime(response.Header, shared)
+	return hasLifetime || response.Header.Get("ETag") != "" || response.Header.Get("Last-Modified") != ""
+}
+
+// freshnessLifetime follows RFC 7234 section 4.2.1. Heuristic freshness is
+// not used: responses without an explicit lifetime are always revalidated.
+func freshnessLifetime(header http.Header, shared bool) (time.Duration, bool) {
+	directives := parseCacheControl(header)
+	if directives.has("no-cache") {
+		return 0, true
+	}
+	if shared {
+		if lifetime, ok := directives.seconds("s-maxage"); ok {
+			return lifetime, true
+		}
+	}
+	if lifetime, ok := directives.seconds("max-age"); ok {
+		return lifetime, true
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			return 0, true
+		}
+
+		date, err := http.ParseTime(header.Get("Date"))
+		if err != nil {
+			return 0, true
+		}
+
+		lifetime := expiresAt.Sub(date)
+		if lifetime < 0 {
+			lifetime = 0
+		}
+		return lifetime, true
+	}
+
+	return 0, false
+}
+
+func staleWhileRevalidate(header http.Header) time.Duration {
+	directives := parseCacheControl(header)
+	if directives.has("must-revalidate") || directives.has("no-cache") {
+		return 0
+	}
+
+	window, _ := directives.seconds("stale-while-revalidate")
+	return window
+}
@@ -0,0 +1,325 @@
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ttanik/http-client/httperror"
+)
+
+// XFromCacheHeader is set on responses served from the cache.
+const XFromCacheHeader = "X-From-Cache"
+
+const defaultRevalidateTimeout = 30 * time.Second
+
+// Requester ...
+type Requester interface {
+	ExecuteRequest(request *http.Request) (*http.Response, *httperror.HTTPError)
+}
+
+// Configs ...
+type Configs struct {
+	// Shared makes the cache behave as a shared cache: private responses are
+	// not stored and s-maxage takes precedence over max-age.
+	Shared bool
+	// RevalidateTimeout bounds background stale-while-revalidate requests.
+	RevalidateTimeout time.Duration
+}
+
+// NewCache ...
+func NewCache(requester Requester, storage Storage, configs Configs) *Cache {
+	if configs.RevalidateTimeout <= 0 {
+		configs.RevalidateTimeout = defaultRevalidateTimeout
+	}
+
+	return &Cache{
+		requester:    requester,
+		storage:      storage,
+		configs:      configs,
+		revalidating: map[string]bool{},
+		now:          time.Now,
+	}
+}
+
+// Cache is an RFC 7234 private (or shared) cache around a Requester. Only
+// GET responses are stored; unsafe methods invalidate the stored entry for
+// their URL.
+type Cache struct {
+	requester Requester
+	storage   Storage
+	configs   Configs
+
+	mutex        sync.Mutex
+	revalidating map[string]bool
+
+	now func() time.Time
+}
+
+type entry struct {
+	StatusCode   int               `json:"status_code"`
+	Header       http.Header       `json:"header"`
+	Body         []byte            `json:"body"`
+	Vary         map[string]string `json:"vary,omitempty"`
+	RequestTime  time.Time         `json:"request_time"`
+	ResponseTime time.Time         `json:"response_time"`
+}
+
+// ExecuteRequest ...
+func (cache *Cache) ExecuteRequest(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	key := cacheKey(request)
+
+	if request.Method != http.MethodGet {
+		response, httpError := cache.requester.ExecuteRequest(request)
+		if httpError == nil && isUnsafe(request.Method) && response.StatusCode < http.StatusBadRequest {
+			cache.storage.Delete(key)
+		}
+		return response, httpError
+	}
+
+	if isConditional(request) {
+		return cache.requester.ExecuteRequest(request)
+	}
+
+	cached, ok := cache.load(key)
+	if !ok || !cached.matches(request) {
+		return cache.fetch(key, request)
+	}
+
+	requestDirectives := parseCacheControl(request.Header)
+	age := cached.age(cache.now())
+	lifetime, _ := freshnessLifetime(cached.Header, cache.configs.Shared)
+	if maxAge, ok := requestDirectives.seconds("max-age"); ok && maxAge < lifetime {
+		lifetime = maxAge
+	}
+
+	if !requestDirectives.has("no-cache") {
+		if age < lifetime {
+			return cached.response(request, age), nil
+		}
+
+		if age < lifetime+staleWhileRevalidate(cached.Header) {
+			response := cached.response(request, age)
+			cache.revalidateInBackground(key, request, cached)
+			return response, nil
+		}
+	}
+
+	return cache.revalidate(key, request, cached)
+}
+
+func (cache *Cache) fetch(key string, request *http.Request) (*http.Response, *httperror.HTTPError) {
+	requestTime := cache.now()
+	response, httpError := cache.requester.ExecuteRequest(request)
+	if httpError != nil {
+		return nil, httpError
+	}
+
+	if !isStorable(request, response, cache.configs.Shared) {
+		return response, nil
+	}
+
+	return cache.store(key, request, response, requestTime)
+}
+
+func (cache *Cache) revalidate(key string, request *http.Request, cached *entry) (*http.Response, *httperror.HTTPError) {
+	conditionalRequest := request.Clone(request.Context())
+	if etag := cached.Header.Get("ETag"); etag != "" {
+		conditionalRequest.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+		conditionalRequest.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	requestTime := cache.now()
+	response, httpError := cache.requester.ExecuteRequest(conditionalRequest)
+	if httpError != nil {
+		return nil, httpError
+	}
+
+	if response.StatusCode == http.StatusNotModified {
+		closeBody(response)
+		cached.refresh(response.Header, requestTime, cache.now())
+		cache.save(key, cached)
+		return cached.response(request, 0), nil
+	}
+
+	if !isStorable(request, response, cache.configs.Shared) {
+		cache.storage.Delete(key)
+		return response, nil
+	}
+
+	return cache.store(key, request, response, requestTime)
+}
+
+func (cache *Cache) revalidateInBackground(key string, request *http.Request, cached *entry) {
+	cache.mutex.Lock()
+	if cache.revalidating[key] {
+		cache.mutex.Unlock()
+		return
+	}
+	cache.revalidating[key] = true
+	cache.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cache.configs.RevalidateTimeout)
+	backgroundRequest := request.Clone(ctx)
+
+	go func() {
+		defer func() {
+			cancel()
+			cache.mutex.Lock()
+			delete(cache.revalidating, key)
+			cache.mutex.Unlock()
+		}()
+
+		response, httpError := cache.revalidate(key, backgroundRequest, cached)
+		if httpError == nil {
+			closeBody(response)
+		}
+	}()
+}
+
+func (cache *Cache) store(key string, request *http.Request, response *http.Response, requestTime time.Time) (*http.Response, *httperror.HTTPError) {
+	var body []byte
+	if response.Body != nil {
+		var err error
+		body, err = io.ReadAll(response.Body)
+		_ = response.Body.Close()
+		if err != nil {
+			return nil, &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "error reading response body",
+				Err:     err,
+				Time:    time.Now(),
+			}
+		}
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	cache.save(key, &entry{
+		StatusCode:   response.StatusCode,
+		Header:       response.Header.Clone(),
+		Body:         body,
+		Vary:         varyValues(request, response.Header),
+		RequestTime:  requestTime,
+		ResponseTime: cache.now(),
+	})
+
+	return response, nil
+}
+
+func (cache *Cache) load(key string) (*entry, bool) {
+	value, ok := cache.storage.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	var cached entry
+	if err := json.Unmarshal(value, &cached); err != nil {
+		cache.storage.Delete(key)
+		return nil, false
+	}
+	return &cached, true
+}
+
+func (cache *Cache) save(key string, cached *entry) {
+	value, err := json.Marshal(cached)
+	if err != nil {
+		return
+	}
+	cache.storage.Set(key, value)
+}
+
+func (cached *entry) matches(request *http.Request) bool {
+	for name, value := range cached.Vary {
+		if request.Header.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// age follows RFC 7234 section 4.2.3.
+func (cached *entry) age(now time.Time) time.Duration {
+	age := cached.ResponseTime.Sub(cached.RequestTime)
+	if seconds, err := strconv.ParseInt(cached.Header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		age += time.Duration(seconds) * time.Second
+	}
+
+	return age + now.Sub(cached.ResponseTime)
+}
+
+// refresh merges the headers of a 304 into the stored entry, RFC 7234
+// section 4.3.4.
+func (cached *entry) refresh(header http.Header, requestTime, responseTime time.Time) {
+	for name, values := range header {
+		if strings.EqualFold(name, "Content-Length") {
+			continue
+		}
+		cached.Header[name] = values
+	}
+	cached.RequestTime = requestTime
+	cached.ResponseTime = responseTime
+}
+
+func (cached *entry) response(request *http.Request, age time.Duration) *http.Response {
+	header := cached.Header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	header.Set(XFromCacheHeader, "1")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cached.StatusCode, http.StatusText(cached.StatusCode)),
+		StatusCode:    cached.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       request,
+	}
+}
+
+func varyValues(request *http.Request, header http.Header) map[string]string {
+	values := map[string]string{}
+	for _, vary := range header.Values("Vary") {
+		for _, name := range strings.Split(vary, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name != "" {
+				values[name] = request.Header.Get(name)
+			}
+		}
+	}
+	return values
+}
+
+func cacheKey(request *http.Request) string {
+	return request.URL.String()
+}
+
+func isConditional(request *http.Request) bool {
+	return request.Header.Get("If-None-Match") != "" || request.Header.Get("If-Modified-Since") != ""
+}
+
+func isUnsafe(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func closeBody(response *http.Response) {
+	if response == nil || response.Body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+}
@@ -0,0 +1,217 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httprequester"
+)
+
+type testServer struct {
+	*httptest.Server
+	hits        atomic.Int64
+	conditional atomic.Int64
+}
+
+func newTestServer(t *testing.T, handler func(writer http.ResponseWriter, request *http.Request)) *testServer {
+	server := &testServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		server.hits.Add(1)
+		if request.Header.Get("If-None-Match") != "" {
+			server.conditional.Add(1)
+		}
+		handler(writer, request)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestCache(configs Configs) *Cache {
+	requester := httprequester.NewHTTPRequester(http.DefaultClient, httpdecoder.NewHTTPDecoder())
+	return NewCache(requester, NewMemoryStorage(1<<20), configs)
+}
+
+func get(t *testing.T, cache *Cache, rawURL string, headers ...string) (*http.Response, string) {
+	request, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	assert.NoError(t, err)
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+
+	response, httpError := cache.ExecuteRequest(request)
+	assert.Nil(t, httpError)
+	body, _ := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	return response, string(body)
+}
+
+func TestCache_ExecuteRequest_FreshHit(t *testing.T) {
+	server := newTestServer(t, func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Cache-Control", "max-age=60")
+		_, _ = writer.Write([]byte("reference"))
+	})
+	cache := newTestCache(Configs{})
+
+	_, body := get(t, cache, server.URL)
+	response, cachedBody := get(t, cache, server.URL)
+
+	assert.Equal(t, "reference", body)
+	assert.Equal(t, "reference", cachedBody)
+	assert.Equal(t, "1", response.Header.Get(XFromCacheHeader))
+	assert.Equal(t, int64(1), server.hits.Load())
+}
+
+func TestCache_ExecuteRequest_NoStore(t *testing.T) {
+	server := newTestServer(t, func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Cache-Control", "no-store, max-age=60")
+		_, _ = writer.Write([]byte("secret"))
+	})
+	cache := newTestCache(Configs{})
+
+	get(t, cache, server.URL)
+	get(t, cache, server.URL)
+
+	assert.Equal(t, int64(2), server.hits.Load())
+}
+
+func TestCache_ExecuteRequest_PrivateInSharedCache(t *testing.T) {
+	server := newTestServer(t, func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Cache-Control", "private, max-age=60")
+		_, _ = writer.Write([]byte("mine"))
+	})
+
+	privateCache := newTestCache(Configs{})
+	get(t, privateCache, server.URL)
+	get(t, privateCache, server.URL)
+	assert.Equal(t, int64(1), server.hits.Load())
+
+	sharedCache := newTestCache(Configs{Shared: true})
+	get(t, sharedCache, server.URL)
+	get(t, sharedCache, server.URL)
+	assert.Equal(t, int64(3), server.hits.Load())
+}
+
+func TestCache_ExecuteRequest_RevalidatesWithETag(t *testing.T) {
+	server := newTestServer(t, func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("ETag", `"v1"`)
+		writer.Header().Set("Cache-Control", "max-age=0")
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = writer.Write([]byte("etagged"))
+	})
+	cache := newTestCache(Configs{})
+
+	get(t, cache, server.URL)
+	response, body := get(t, cache, server.URL)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "etagged", body)
+	assert.Equal(t, "1", response.Header.Get(XFromCacheHeader))
+	assert.Equal(t, int64(1), server.conditional.Load())
+}
+
+func TestCache_ExecuteRequest_Vary(t *testing.T) {
+	server := newTestServer(t, func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Cache-Control", "max-age=60")
+		writer.Header().Set("Vary", "Accept-Language")
+		_, _ = writer.Write([]byte(request.Header.Get("Accept-Language")))
+	})
+	cache := newTestCache(Configs{})
+
+	_, english := get(t, cache, server.URL, "Accept-Language", "en")
+	_, portuguese := get(t, cache, server.URL, "Accept-Language", "pt")
+	_, cachedPortuguese := get(t, cache, server.URL, "Accept-Language", "pt")
+
+	assert.Equal(t, "en", english)
+	assert.Equal(t, "pt", portuguese)
+	assert.Equal(t, "pt", cachedPortuguese)
+	assert.Equal(t, int64(2), server.hits.Load())
+}
+
+func TestCache_ExecuteRequest_StaleWhileRevalidate(t *testing.T) {
+	var version atomic.Int64
+	server := newTestServer(t, func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Cache-Control", "max-age=1, stale-while-revalidate=60")
+		if version.Add(1) == 1 {
+			_, _ = writer.Write([]byte("old"))
+			return
+		}
+		_, _ = writer.Write([]byte("new"))
+	})
+	cache := newTestCache(Configs{})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	get(t, cache, server.URL)
+	now = now.Add(5 * time.Second)
+	_, stale := get(t, cache, server.URL)
+
+	assert.Equal(t, "old", stale)
+	assert.Eventually(t, func() bool {
+		cached, ok := cache.load(cacheKey(&http.Request{URL: mustParse(t, server.URL)}))
+		return ok && string(cached.Body) == "new"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestCache_ExecuteRequest_UnsafeMethodInvalidates(t *testing.T) {
+	server := newTestServer(t, func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Cache-Control", "max-age=60")
+		_, _ = writer.Write([]byte("reference"))
+	})
+	cache := newTestCache(Configs{})
+
+	get(t, cache, server.URL)
+	request, _ := http.NewRequest(http.MethodDelete, server.URL, nil)
+	response, httpError := cache.ExecuteRequest(request)
+	assert.Nil(t, httpError)
+	_ = response.Body.Close()
+	get(t, cache, server.URL)
+
+	assert.Equal(t, int64(3), server.hits.Load())
+}
+
+func TestMemoryStorage_EvictsLeastRecentlyUsed(t *testing.T) {
+	storage := NewMemoryStorage(10)
+	storage.Set("a", []byte("aaaa"))
+	storage.Set("b", []byte("bbbb"))
+	storage.Get("a")
+	storage.Set("c", []byte("cccc"))
+
+	_, hasA := storage.Get("a")
+	_, hasB := storage.Get("b")
+	assert.True(t, hasA)
+	assert.False(t, hasB)
+	assert.Equal(t, int64(8), storage.Size())
+
+	storage.Set("big", make([]byte, 11))
+	_, hasBig := storage.Get("big")
+	assert.False(t, hasBig)
+}
+
+func TestDiskStorage(t *testing.T) {
+	storage := NewDiskStorage(t.TempDir())
+	storage.Set("key", []byte("value"))
+
+	value, ok := storage.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, "value", string(value))
+
+	storage.Delete("key")
+	_, ok = storage.Get("key")
+	assert.False(t, ok)
+}
+
+func mustParse(t *testing.T, rawURL string) *url.URL {
+	parsedURL, err := url.Parse(rawURL)
+	assert.NoError(t, err)
+	return parsedURL
+}
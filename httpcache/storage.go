@@ -0,0 +1,153 @@
+package httpcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Storage ...
+type Storage interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(key string)
+}
+
+// NewMemoryStorage returns an in-memory LRU storage that evicts the least
+// recently used entries once maxBytes is exceeded.
+func NewMemoryStorage(maxBytes int64) *MemoryStorage {
+	return &MemoryStorage{
+		maxBytes: maxBytes,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// MemoryStorage ...
+type MemoryStorage struct {
+	maxBytes int64
+
+	mutex   sync.Mutex
+	size    int64
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type memoryEntry struct {
+	key   string
+	value []byte
+}
+
+// Get ...
+func (storage *MemoryStorage) Get(key string) ([]byte, bool) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	element, ok := storage.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	storage.order.MoveToFront(element)
+	return element.Value.(*memoryEntry).value, true
+}
+
+// Set ...
+func (storage *MemoryStorage) Set(key string, value []byte) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	storage.remove(key)
+	if int64(len(value)) > storage.maxBytes {
+		return
+	}
+
+	storage.entries[key] = storage.order.PushFront(&memoryEntry{key: key, value: value})
+	storage.size += int64(len(value))
+
+	for storage.size > storage.maxBytes {
+		storage.remove(storage.order.Back().Value.(*memoryEntry).key)
+	}
+}
+
+// Delete ...
+func (storage *MemoryStorage) Delete(key string) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	storage.remove(key)
+}
+
+// Size returns the number of bytes currently stored.
+func (storage *MemoryStorage) Size() int64 {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	return storage.size
+}
+
+func (storage *MemoryStorage) remove(key string) {
+	element, ok := storage.entries[key]
+	if !ok {
+		return
+	}
+
+	storage.order.Remove(element)
+	delete(storage.entries, key)
+	storage.size -= int64(len(element.Value.(*memoryEntry).value))
+}
+
+// NewDiskStorage stores one file per entry in dir.
+func NewDiskStorage(dir string) *DiskStorage {
+	return &DiskStorage{dir: dir}
+}
+
+// DiskStorage ...
+type DiskStorage struct {
+	dir string
+}
+
+// Get ...
+func (storage *DiskStorage) Get(key string) ([]byte, bool) {
+	value, err := os.ReadFile(storage.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set writes through a temporary file so readers never see partial entries.
+func (storage *DiskStorage) Set(key string, value []byte) {
+	if err := os.MkdirAll(storage.dir, 0o700); err != nil {
+		return
+	}
+
+	file, err := os.CreateTemp(storage.dir, "tmp-")
+	if err != nil {
+		return
+	}
+
+	_, writeErr := file.Write(value)
+	closeErr := file.Close()
+	if writeErr != nil || closeErr != nil {
+		_ = os.Remove(file.Name())
+		return
+	}
+
+	if err := os.Rename(file.Name(), storage.path(key)); err != nil {
+		_ = os.Remove(file.Name())
+	}
+}
+
+// Delete ...
+func (storage *DiskStorage) Delete(key string) {
+	_ = os.Remove(storage.path(key))
+}
+
+func (storage *DiskStorage) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(storage.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,292 @@
+package httpcassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Mode ...
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette and never touches the
+	// network.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests through the transport and records them.
+	ModeRecord
+)
+
+// RedactedValue replaces the value of redacted headers.
+const RedactedValue = "REDACTED"
+
+// ErrInteractionNotFound is returned in replay mode for requests the
+// cassette has no interaction for.
+var ErrInteractionNotFound = errors.New("no recorded interaction matches request")
+
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// Transport ...
+type Transport interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Configs ...
+type Configs struct {
+	Mode Mode
+	// Transport performs real requests in record mode. Defaults to
+	// http.DefaultClient.
+	Transport Transport
+	// RedactHeaders are redacted in addition to the credential headers that
+	// are always redacted.
+	RedactHeaders []string
+	// Matchers default to DefaultMatchers.
+	Matchers []Matcher
+	// AllowRepeats lets an interaction answer more than one request.
+	AllowRepeats bool
+}
+
+// Cassette records and replays HTTP interactions. It implements the
+// Requester expected by httprequester.NewHTTPRequester, so it can stand in
+// for http.Client in tests. Cassettes ending in .json are stored as JSON,
+// anything else as YAML.
+type Cassette struct {
+	path         string
+	mode         Mode
+	transport    Transport
+	redacted     map[string]bool
+	matchers     []Matcher
+	allowRepeats bool
+
+	mutex        sync.Mutex
+	interactions []Interaction
+	used         []bool
+	unmatched    []string
+}
+
+// NewCassette loads the cassette at path in replay mode, or starts an empty
+// one in record mode.
+func NewCassette(path string, configs Configs) (*Cassette, error) {
+	cassette := &Cassette{
+		path:         path,
+		mode:         configs.Mode,
+		transport:    configs.Transport,
+		redacted:     map[string]bool{},
+		matchers:     configs.Matchers,
+		allowRepeats: configs.AllowRepeats,
+	}
+	if cassette.transport == nil {
+		cassette.transport = http.DefaultClient
+	}
+	if cassette.matchers == nil {
+		cassette.matchers = DefaultMatchers()
+	}
+	for _, header := range append(defaultRedactedHeaders, configs.RedactHeaders...) {
+		cassette.redacted[http.CanonicalHeaderKey(header)] = true
+	}
+
+	if cassette.mode == ModeReplay {
+		interactions, err := loadInteractions(path)
+		if err != nil {
+			return nil, fmt.Errorf("loading cassette %s: %w", path, err)
+		}
+		cassette.interactions = interactions
+		cassette.used = make([]bool, len(interactions))
+	}
+
+	return cassette, nil
+}
+
+// Do ...
+func (cassette *Cassette) Do(request *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if cassette.mode == ModeRecord {
+		return cassette.record(request, body)
+	}
+	return cassette.replay(request, body)
+}
+
+func (cassette *Cassette) record(request *http.Request, body []byte) (*http.Response, error) {
+	response, err := cassette.transport.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	method := request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method:  method,
+			URL:     request.URL.String(),
+			Headers: cassette.redact(request.Header),
+		},
+		Response: RecordedResponse{
+			Status:  response.StatusCode,
+			Headers: cassette.redact(response.Header),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(body)
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(responseBody)
+
+	cassette.mutex.Lock()
+	cassette.interactions = append(cassette.interactions, interaction)
+	cassette.used = append(cassette.used, true)
+	cassette.mutex.Unlock()
+
+	return response, nil
+}
+
+func (cassette *Cassette) replay(request *http.Request, body []byte) (*http.Response, error) {
+	cassette.mutex.Lock()
+	defer cassette.mutex.Unlock()
+
+	match := -1
+	for index, interaction := range cassette.interactions {
+		if cassette.used[index] && !cassette.allowRepeats {
+			continue
+		}
+		if cassette.matches(request, body, interaction.Request) {
+			match = index
+			break
+		}
+	}
+
+	if match < 0 {
+		description := request.Method + " " + request.URL.String()
+		cassette.unmatched = append(cassette.unmatched, description)
+		return nil, fmt.Errorf("%w: %s", ErrInteractionNotFound, description)
+	}
+
+	cassette.used[match] = true
+	recorded := cassette.interactions[match].Response
+	responseBody, err := recorded.BodyBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(responseBody)),
+		ContentLength: int64(len(responseBody)),
+		Request:       request,
+	}, nil
+}
+
+func (cassette *Cassette) matches(request *http.Request, body []byte, recorded RecordedRequest) bool {
+	for _, matcher := range cassette.matchers {
+		if !matcher(request, body, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cassette *Cassette) redact(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for name := range redacted {
+		if cassette.redacted[http.CanonicalHeaderKey(name)] {
+			redacted[name] = []string{RedactedValue}
+		}
+	}
+	return redacted
+}
+
+// Save writes the recorded interactions to the cassette file. It is a
+// no-op in replay mode.
+func (cassette *Cassette) Save() error {
+	if cassette.mode != ModeRecord {
+		return nil
+	}
+
+	cassette.mutex.Lock()
+	defer cassette.mutex.Unlock()
+	return saveInteractions(cassette.path, cassette.interactions)
+}
+
+// Unused returns the interactions that no request has replayed yet.
+func (cassette *Cassette) Unused() []Interaction {
+	cassette.mutex.Lock()
+	defer cassette.mutex.Unlock()
+
+	var unused []Interaction
+	for index, interaction := range cassette.interactions {
+		if !cassette.used[index] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// Unmatched returns "METHOD URL" for every request that found no
+// interaction.
+func (cassette *Cassette) Unmatched() []string {
+	cassette.mutex.Lock()
+	defer cassette.mutex.Unlock()
+
+	return append([]string(nil), cassette.unmatched...)
+}
+
+// Check returns an error describing unmatched requests and unused
+// interactions, or nil when the cassette was replayed exactly.
+func (cassette *Cassette) Check() error {
+	var problems []string
+	for _, description := range cassette.Unmatched() {
+		problems = append(problems, "unmatched request "+description)
+	}
+	for _, interaction := range cassette.Unused() {
+		problems = append(problems, "unused interaction "+interaction.Request.Method+" "+interaction.Request.URL)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("cassette %s: %s", cassette.path, strings.Join(problems, "; "))
+}
+
+// readRequestBody reads the body and puts an equivalent one back, so the
+// transport still gets to send it.
+func readRequestBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(request.Body)
+	_ = request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	request.Body = io.NopCloser(bytes.NewReader(body))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, nil
+}
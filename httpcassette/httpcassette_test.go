@@ -0,0 +1,153 @@
+package httpcassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		writer.Header().Set("Set-Cookie", "session=secret")
+		writer.Header().Set("Content-Type", "text/plain")
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write([]byte("echo:" + string(body)))
+	}))
+}
+
+func doRequest(t *testing.T, cassette *Cassette, method string, url string, body string) (*http.Response, error) {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NoError(t, err)
+	request.Header.Set("Authorization", "Bearer token")
+	request.Header.Set("X-Tenant", "rain")
+	return cassette.Do(request)
+}
+
+func readAll(t *testing.T, response *http.Response) string {
+	body, err := io.ReadAll(response.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, response.Body.Close())
+	return string(body)
+}
+
+func record(t *testing.T, path string, url string, configs Configs) {
+	configs.Mode = ModeRecord
+	recorder, err := NewCassette(path, configs)
+	assert.NoError(t, err)
+
+	response, err := doRequest(t, recorder, http.MethodPost, url+"/users", `{"name":"rain"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `echo:{"name":"rain"}`, readAll(t, response))
+	assert.NoError(t, recorder.Save())
+}
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	for _, name := range []string{"users.yaml", "users.json"} {
+		path := filepath.Join(t.TempDir(), "fixtures", name)
+		record(t, path, server.URL, Configs{RedactHeaders: []string{"X-Tenant"}})
+
+		content, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(content), "Bearer token", name)
+		assert.NotContains(t, string(content), "session=secret", name)
+		interactions, err := loadInteractions(path)
+		assert.NoError(t, err)
+		assert.Equal(t, RedactedValue, interactions[0].Request.Headers.Get("X-Tenant"), name)
+		assert.Equal(t, RedactedValue, interactions[0].Response.Headers.Get("Set-Cookie"), name)
+
+		player, err := NewCassette(path, Configs{})
+		assert.NoError(t, err)
+		response, err := doRequest(t, player, http.MethodPost, server.URL+"/users", `{ "name": "rain" }`)
+
+		assert.NoError(t, err, name)
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+		assert.Equal(t, "text/plain", response.Header.Get("Content-Type"))
+		assert.Equal(t, `echo:{"name":"rain"}`, readAll(t, response))
+		assert.NoError(t, player.Check())
+	}
+}
+
+func TestCassette_ReplayReportsUnmatchedAndUnused(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	record(t, path, server.URL, Configs{})
+
+	player, err := NewCassette(path, Configs{})
+	assert.NoError(t, err)
+	_, err = doRequest(t, player, http.MethodPost, server.URL+"/users", `{"name":"snow"}`)
+
+	assert.ErrorIs(t, err, ErrInteractionNotFound)
+	assert.Equal(t, []string{"POST " + server.URL + "/users"}, player.Unmatched())
+	assert.Len(t, player.Unused(), 1)
+	assert.EqualError(t, player.Check(), "cassette "+path+": unmatched request POST "+server.URL+
+		"/users; unused interaction POST "+server.URL+"/users")
+}
+
+func TestCassette_ReplayUsesInteractionsOnce(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	record(t, path, server.URL, Configs{})
+
+	player, _ := NewCassette(path, Configs{})
+	_, err := doRequest(t, player, http.MethodPost, server.URL+"/users", `{"name":"rain"}`)
+	assert.NoError(t, err)
+	_, err = doRequest(t, player, http.MethodPost, server.URL+"/users", `{"name":"rain"}`)
+	assert.ErrorIs(t, err, ErrInteractionNotFound)
+
+	repeating, _ := NewCassette(path, Configs{AllowRepeats: true})
+	for i := 0; i < 2; i++ {
+		_, err = doRequest(t, repeating, http.MethodPost, server.URL+"/users", `{"name":"rain"}`)
+		assert.NoError(t, err)
+	}
+}
+
+func TestCassette_CustomMatchers(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	record(t, path, server.URL, Configs{})
+
+	player, _ := NewCassette(path, Configs{Matchers: []Matcher{MatchMethod, MatchHeader("X-Tenant")}})
+	response, err := doRequest(t, player, http.MethodPost, "http://elsewhere/other", "ignored")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+}
+
+func TestCassette_BinaryBodies(t *testing.T) {
+	binary := string([]byte{0x1f, 0x8b, 0xff, 0x00})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(binary))
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "binary.yaml")
+
+	recorder, _ := NewCassette(path, Configs{Mode: ModeRecord})
+	response, err := doRequest(t, recorder, http.MethodGet, server.URL, "")
+	assert.NoError(t, err)
+	assert.Equal(t, binary, readAll(t, response))
+	assert.NoError(t, recorder.Save())
+
+	player, _ := NewCassette(path, Configs{})
+	response, err = doRequest(t, player, http.MethodGet, server.URL, "")
+	assert.NoError(t, err)
+	assert.Equal(t, binary, readAll(t, response))
+}
+
+func TestNewCassette_MissingFile(t *testing.T) {
+	_, err := NewCassette(filepath.Join(t.TempDir(), "missing.yaml"), Configs{})
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
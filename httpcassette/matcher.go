@@ -0,0 +1,57 @@
+package httpcassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+)
+
+// Matcher reports whether a live request matches a recorded one. body is
+// the live request body, already read.
+type Matcher func(request *http.Request, body []byte, recorded RecordedRequest) bool
+
+// DefaultMatchers match on method, URL and body.
+func DefaultMatchers() []Matcher {
+	return []Matcher{MatchMethod, MatchURL, MatchBody}
+}
+
+// MatchMethod ...
+func MatchMethod(request *http.Request, _ []byte, recorded RecordedRequest) bool {
+	method := request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	return method == recorded.Method
+}
+
+// MatchURL ...
+func MatchURL(request *http.Request, _ []byte, recorded RecordedRequest) bool {
+	return request.URL.String() == recorded.URL
+}
+
+// MatchBody compares bodies byte for byte, or structurally when both are
+// JSON so that key order does not matter.
+func MatchBody(_ *http.Request, body []byte, recorded RecordedRequest) bool {
+	recordedBody, err := recorded.BodyBytes()
+	if err != nil {
+		return false
+	}
+	if bytes.Equal(body, recordedBody) {
+		return true
+	}
+
+	var live, saved interface{}
+	if json.Unmarshal(body, &live) != nil || json.Unmarshal(recordedBody, &saved) != nil {
+		return false
+	}
+	return reflect.DeepEqual(live, saved)
+}
+
+// MatchHeader matches on the value of a header. Redacted headers never
+// match, so do not use it with one.
+func MatchHeader(name string) Matcher {
+	return func(request *http.Request, _ []byte, recorded RecordedRequest) bool {
+		return request.Header.Get(name) == recorded.Headers.Get(name)
+	}
+}
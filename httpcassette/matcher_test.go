@@ -0,0 +1,25 @@
+package httpcassette
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchBody(t *testing.T) {
+	request, _ := http.NewRequest(http.MethodPost, "http://rain.us", nil)
+
+	assert.True(t, MatchBody(request, []byte("plain"), RecordedRequest{Body: "plain"}))
+	assert.True(t, MatchBody(request, []byte(`{"a":1,"b":2}`), RecordedRequest{Body: `{"b":2,"a":1}`}))
+	assert.False(t, MatchBody(request, []byte(`{"a":1}`), RecordedRequest{Body: `{"a":2}`}))
+	assert.True(t, MatchBody(request, []byte{0xff}, RecordedRequest{Body: "/w==", BodyEncoding: base64Encoding}))
+}
+
+func TestMatchMethodAndURL(t *testing.T) {
+	request, _ := http.NewRequest("", "http://rain.us/users?page=2", nil)
+
+	assert.True(t, MatchMethod(request, nil, RecordedRequest{Method: http.MethodGet}))
+	assert.True(t, MatchURL(request, nil, RecordedRequest{URL: "http://rain.us/users?page=2"}))
+	assert.False(t, MatchURL(request, nil, RecordedRequest{URL: "http://rain.us/users"}))
+}
@@ -0,0 +1,151 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ttanik/http-client/httperror"
+)
+
+const defaultBatchConcurrency = 8
+
+// BatchOptions ...
+type BatchOptions struct {
+	// Concurrency bounds the requests in flight, 8 by default.
+	Concurrency int
+	// FailFast cancels the outstanding requests after the first failure.
+	// Requests that never started fail with context.Canceled.
+	FailFast bool
+}
+
+// BatchResult is the outcome of one request of a batch. The body of Response
+// has already been read.
+type BatchResult struct {
+	Response *Response
+	Err      *httperror.HTTPError
+}
+
+// BatchFailure ...
+type BatchFailure struct {
+	Index int
+	Err   *httperror.HTTPError
+}
+
+// BatchError aggregates the failed requests of a batch. errors.Is and
+// errors.As look through every individual error.
+type BatchError struct {
+	Failures []BatchFailure
+	Total    int
+}
+
+// Error ...
+func (batchError *BatchError) Error() string {
+	descriptions := make([]string, len(batchError.Failures))
+	for index, failure := range batchError.Failures {
+		descriptions[index] = fmt.Sprintf("#%d: %s", failure.Index, failure.Err)
+	}
+	return fmt.Sprintf("%d of %d requests failed: %s", len(batchError.Failures), batchError.Total, strings.Join(descriptions, "; "))
+}
+
+// Unwrap ...
+func (batchError *BatchError) Unwrap() []error {
+	errs := make([]error, len(batchError.Failures))
+	for index, failure := range batchError.Failures {
+		errs[index] = failure.Err
+	}
+	return errs
+}
+
+// Is lets errors.Is match any individual error, also before Go 1.20.
+func (batchError *BatchError) Is(target error) bool {
+	for _, err := range batchError.Unwrap() {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As lets errors.As match any individual error, also before Go 1.20.
+func (batchError *BatchError) As(target interface{}) bool {
+	for _, err := range batchError.Unwrap() {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Batch sends every request and waits for all of them. Results keep the order
+// of requests. The error is a *BatchError when any request failed.
+func (client *Client) Batch(ctx context.Context, requests []HTTPRequestBuilder, options BatchOptions) ([]BatchResult, error) {
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]BatchResult, len(requests))
+	indexes := make(chan int)
+	var workers sync.WaitGroup
+	for worker := 0; worker < concurrency && worker < len(requests); worker++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range indexes {
+				results[index] = client.batchItem(ctx, requests[index])
+				if results[index].Err != nil && options.FailFast {
+					cancel()
+				}
+			}
+		}()
+	}
+
+	next := 0
+	for next < len(requests) && ctx.Err() == nil {
+		select {
+		case indexes <- next:
+			next++
+		case <-ctx.Done():
+		}
+	}
+	close(indexes)
+	workers.Wait()
+
+	for index := next; index < len(requests); index++ {
+		results[index].Err = cancelledError(ctx.Err())
+	}
+
+	batchError := &BatchError{Total: len(requests)}
+	for index, result := range results {
+		if result.Err != nil {
+			batchError.Failures = append(batchError.Failures, BatchFailure{Index: index, Err: result.Err})
+		}
+	}
+	if len(batchError.Failures) > 0 {
+		return results, batchError
+	}
+	return results, nil
+}
+
+// batchItem reads the whole body so that it outlives the batch context.
+func (client *Client) batchItem(ctx context.Context, builder HTTPRequestBuilder) BatchResult {
+	request, httpError := builder.WithContext(ctx).Build()
+	if httpError != nil {
+		return BatchResult{Err: httpError}
+	}
+
+	response, httpError := client.Do(request)
+	if httpError != nil {
+		return BatchResult{Err: httpError}
+	}
+	if _, httpError = response.Bytes(); httpError != nil {
+		return BatchResult{Err: httpError}
+	}
+	return BatchResult{Response: response}
+}
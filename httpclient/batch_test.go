@@ -0,0 +1,129 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httperror"
+)
+
+func newBatchServer(t *testing.T, inFlight *int32, maxInFlight *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		current := atomic.AddInt32(inFlight, 1)
+		defer atomic.AddInt32(inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(maxInFlight, seen, current) {
+				break
+			}
+		}
+
+		id, _ := strconv.Atoi(request.URL.Query().Get("id"))
+		select {
+		case <-time.After(time.Duration(10-id) * time.Millisecond):
+		case <-request.Context().Done():
+			return
+		}
+		if request.URL.Query().Get("fail") != "" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = writer.Write([]byte(strconv.Itoa(id)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func batchRequests(client *Client, count int, failing ...int) []HTTPRequestBuilder {
+	requests := make([]HTTPRequestBuilder, count)
+	for index := range requests {
+		requests[index] = client.NewRequestBuilder(context.Background()).
+			WithQueryParam("id", strconv.Itoa(index))
+		for _, failure := range failing {
+			if failure == index {
+				requests[index] = requests[index].WithQueryParam("fail", "true")
+			}
+		}
+	}
+	return requests
+}
+
+func TestClient_Batch(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := newBatchServer(t, &inFlight, &maxInFlight)
+	client := NewHTTPClient(WithBaseURL(server.URL))
+
+	results, err := client.Batch(context.Background(), batchRequests(client, 10), BatchOptions{Concurrency: 3})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 10)
+	for index, result := range results {
+		assert.Nil(t, result.Err)
+		assert.Equal(t, strconv.Itoa(index), result.Response.String())
+	}
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(3))
+}
+
+func TestClient_Batch_CollectAll(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := newBatchServer(t, &inFlight, &maxInFlight)
+	client := NewHTTPClient(WithBaseURL(server.URL), WithErrorPolicy(ErrorOnStatus(http.StatusBadRequest)))
+
+	results, err := client.Batch(context.Background(), batchRequests(client, 5, 1, 3), BatchOptions{})
+
+	var batchError *BatchError
+	assert.True(t, errors.As(err, &batchError))
+	assert.Equal(t, 5, batchError.Total)
+	assert.Len(t, batchError.Failures, 2)
+	assert.Equal(t, 1, batchError.Failures[0].Index)
+	assert.Equal(t, 3, batchError.Failures[1].Index)
+	assert.Contains(t, err.Error(), "2 of 5 requests failed: #1: status: 404")
+
+	var httpError *httperror.HTTPError
+	assert.True(t, errors.As(err, &httpError))
+	assert.Equal(t, http.StatusNotFound, httpError.Status)
+
+	assert.Equal(t, "4", results[4].Response.String())
+	assert.Nil(t, results[1].Response)
+}
+
+func TestClient_Batch_FailFast(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := newBatchServer(t, &inFlight, &maxInFlight)
+	client := NewHTTPClient(WithBaseURL(server.URL), WithErrorPolicy(ErrorOnStatus(http.StatusBadRequest)))
+
+	results, err := client.Batch(context.Background(), batchRequests(client, 20, 0), BatchOptions{Concurrency: 2, FailFast: true})
+
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, http.StatusNotFound, results[0].Err.Status)
+	assert.True(t, errors.Is(results[19].Err, context.Canceled))
+	assert.Greater(t, len(err.(*BatchError).Failures), 10)
+}
+
+func TestClient_Batch_BuildError(t *testing.T) {
+	client := NewHTTPClient()
+	requests := []HTTPRequestBuilder{client.NewRequestBuilder(context.Background()).WithEndpoint("http://rain.us/{id}").WithPathParam("other", "1")}
+
+	results, err := client.Batch(context.Background(), requests, BatchOptions{})
+
+	assert.Error(t, err)
+	assert.Equal(t, "unresolved path parameter", results[0].Err.Message)
+}
+
+func TestBatchError_Is(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	batchError := &BatchError{Total: 2, Failures: []BatchFailure{
+		{Index: 0, Err: &httperror.HTTPError{Status: http.StatusBadGateway, Message: "bad"}},
+		{Index: 1, Err: &httperror.HTTPError{Status: http.StatusInternalServerError, Message: "wrapped", Err: sentinel}},
+	}}
+
+	assert.True(t, batchError.Is(sentinel))
+	assert.False(t, batchError.Is(context.Canceled))
+}
@@ -0,0 +1,227 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ttanik/http-client/httperror"
+	"github.com/ttanik/http-client/httptiming"
+)
+
+type coalescedCall struct {
+	done     chan struct{}
+	waiters  int
+	cancel   context.CancelFunc
+	recorder *httptiming.Recorder
+
+	response *http.Response
+	body     []byte
+	err      *httperror.HTTPError
+	timing   httptiming.Timing
+}
+
+type coalescer struct {
+	requester    Requester
+	headers      []string
+	maxBodyBytes int64
+
+	mutex sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+// credentialHeaders are always part of the key, so that callers never share
+// a response fetched with someone else's credentials.
+var credentialHeaders = []string{"Authorization", "Cookie"}
+
+func newCoalescer(requester Requester, headers []string, maxBodyBytes int64) *coalescer {
+	keyHeaders := append([]string(nil), credentialHeaders...)
+	for _, header := range headers {
+		header = http.CanonicalHeaderKey(header)
+		if !containsHeader(keyHeaders, header) {
+			keyHeaders = append(keyHeaders, header)
+		}
+	}
+
+	return &coalescer{
+		requester:    requester,
+		headers:      keyHeaders,
+		maxBodyBytes: maxBodyBytes,
+		calls:        map[string]*coalescedCall{},
+	}
+}
+
+func containsHeader(headers []string, header string) bool {
+	for _, existing := range headers {
+		if existing == header {
+			return true
+		}
+	}
+	return false
+}
+
+// canCoalesce leaves out streaming requests, whose bodies cannot be buffered
+// for every caller.
+func (c *coalescer) canCoalesce(request *http.Request) bool {
+	return request.Method == http.MethodGet &&
+		(request.Body == nil || request.Body == http.NoBody) &&
+		!acceptsStream(request.Header.Values("Accept"))
+}
+
+func acceptsStream(accept []string) bool {
+	for _, value := range accept {
+		value = strings.ToLower(value)
+		if strings.Contains(value, "text/event-stream") || strings.Contains(value, "ndjson") || strings.Contains(value, "jsonl") {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *coalescer) key(request *http.Request) string {
+	var key strings.Builder
+	key.WriteString(request.Method)
+	key.WriteString(" ")
+	key.WriteString(request.URL.String())
+	for _, header := range c.headers {
+		key.WriteString("\n")
+		key.WriteString(header)
+		key.WriteString(": ")
+		key.WriteString(strings.Join(request.Header.Values(header), ","))
+	}
+	return key.String()
+}
+
+func (c *coalescer) execute(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	key := c.key(request)
+
+	c.mutex.Lock()
+	call, ok := c.calls[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(detachedContext{parent: request.Context()})
+		// The shared request is recorded apart and its phases handed to
+		// every waiter, the first one included.
+		ctx, recorder := httptiming.WithAttempt(ctx)
+		call = &coalescedCall{done: make(chan struct{}), cancel: cancel, recorder: recorder}
+		c.calls[key] = call
+		go c.run(key, call, request.Clone(ctx))
+	}
+	call.waiters++
+	c.mutex.Unlock()
+
+	select {
+	case <-call.done:
+		return call.result(request)
+	case <-request.Context().Done():
+		c.leave(key, call)
+		return nil, cancelledError(request.Context().Err())
+	}
+}
+
+// run executes the shared request. It is detached from the callers' contexts
+// so that one waiter giving up does not cancel it for the others.
+func (c *coalescer) run(key string, call *coalescedCall, request *http.Request) {
+	response, httpError := c.requester.ExecuteRequest(request)
+	if httpError == nil && response.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(response.Body, c.maxBodyBytes+1))
+		_ = response.Body.Close()
+		switch {
+		case err != nil:
+			httpError = &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "error reading response body",
+				Err:     err,
+				Time:    time.Now(),
+			}
+		case int64(len(body)) > c.maxBodyBytes:
+			httpError = &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "response body too large",
+				Err:     fmt.Errorf("body exceeds %d bytes", c.maxBodyBytes),
+				Time:    time.Now(),
+			}
+		}
+		call.body = body
+	}
+	call.response = response
+	call.err = httpError
+	call.timing = call.recorder.Finish()
+	call.cancel()
+
+	c.mutex.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	c.mutex.Unlock()
+
+	close(call.done)
+}
+
+// leave cancels the shared request once its last waiter has gone.
+func (c *coalescer) leave(key string, call *coalescedCall) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+
+	call.cancel()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+}
+
+// result gives every waiter its own copy of the response and body.
+func (call *coalescedCall) result(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	httptiming.FromContext(request.Context()).Adopt(call.timing)
+	if call.err != nil {
+		return nil, call.err
+	}
+
+	response := *call.response
+	response.Header = call.response.Header.Clone()
+	response.Request = request
+	if call.response.Body != nil {
+		response.Body = io.NopCloser(bytes.NewReader(call.body))
+	}
+	return &response, nil
+}
+
+func cancelledError(err error) *httperror.HTTPError {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &httperror.HTTPError{
+			Status:  http.StatusGatewayTimeout,
+			Message: "request timed out",
+			Err:     err,
+			Time:    time.Now(),
+		}
+	}
+
+	return &httperror.HTTPError{
+		Status:  http.StatusInternalServerError,
+		Message: "error executing request",
+		Err:     err,
+		Time:    time.Now(),
+	}
+}
+
+// detachedContext keeps the values of its parent but not its cancellation.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (ctx detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (ctx detachedContext) Done() <-chan struct{} { return nil }
+
+func (ctx detachedContext) Err() error { return nil }
+
+func (ctx detachedContext) Value(key interface{}) interface{} { return ctx.parent.Value(key) }
@@ -0,0 +1,199 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient/mocks"
+	"github.com/ttanik/http-client/httperror"
+)
+
+type blockingRequester struct {
+	calls    atomic.Int64
+	release  chan struct{}
+	canceled atomic.Bool
+}
+
+func (requester *blockingRequester) ExecuteRequest(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	requester.calls.Add(1)
+	select {
+	case <-requester.release:
+	case <-request.Context().Done():
+		requester.canceled.Store(true)
+		return nil, cancelledError(request.Context().Err())
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"X-Test": []string{"shared"}},
+		Body:       io.NopCloser(strings.NewReader("payload")),
+	}, nil
+}
+
+func waitForCalls(t *testing.T, requester *blockingRequester) {
+	assert.Eventually(t, func() bool { return requester.calls.Load() == 1 }, time.Second, time.Millisecond)
+}
+
+func TestClient_Get_Coalescing(t *testing.T) {
+	requester := &blockingRequester{release: make(chan struct{})}
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(new(mocks.Marshaller)), WithCoalescing())
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 10)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, httpError := client.Get(context.Background(), "http://rain.us/test")
+			assert.Nil(t, httpError)
+			body, _ := io.ReadAll(response.Body)
+			bodies[i] = string(body)
+		}(i)
+	}
+
+	waitForCalls(t, requester)
+	time.Sleep(10 * time.Millisecond)
+	close(requester.release)
+	wg.Wait()
+
+	assert.Equal(t, int64(1), requester.calls.Load())
+	for _, body := range bodies {
+		assert.Equal(t, "payload", body)
+	}
+}
+
+func TestClient_Get_CoalescingWaiterCancellation(t *testing.T) {
+	requester := &blockingRequester{release: make(chan struct{})}
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(new(mocks.Marshaller)), WithCoalescing())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelledResult := make(chan *httperror.HTTPError)
+	go func() {
+		_, httpError := client.Get(ctx, "http://rain.us/test")
+		cancelledResult <- httpError
+	}()
+	waitForCalls(t, requester)
+
+	result := make(chan *http.Response)
+	go func() {
+		response, _ := client.Get(context.Background(), "http://rain.us/test")
+		result <- response
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	cancel()
+	assert.Equal(t, http.StatusInternalServerError, (<-cancelledResult).Status)
+	assert.False(t, requester.canceled.Load())
+
+	close(requester.release)
+	response := <-result
+	assert.Equal(t, "shared", response.Header.Get("X-Test"))
+}
+
+func TestClient_Get_CoalescingLastWaiterCancels(t *testing.T) {
+	requester := &blockingRequester{release: make(chan struct{})}
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(new(mocks.Marshaller)), WithCoalescing())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, httpError := client.Get(ctx, "http://rain.us/test")
+
+	assert.Equal(t, http.StatusGatewayTimeout, httpError.Status)
+	assert.Eventually(t, requester.canceled.Load, time.Second, time.Millisecond)
+}
+
+func TestCoalescer_Key_SelectedHeaders(t *testing.T) {
+	coalescer := newCoalescer(nil, []string{"Authorization"}, defaultMaxBodyBytes)
+	first, _ := http.NewRequest(http.MethodGet, "http://rain.us/test", nil)
+	first.Header.Set("Authorization", "a")
+	first.Header.Set("X-Ignored", "1")
+	second, _ := http.NewRequest(http.MethodGet, "http://rain.us/test", nil)
+	second.Header.Set("Authorization", "b")
+	third, _ := http.NewRequest(http.MethodGet, "http://rain.us/test", nil)
+	third.Header.Set("Authorization", "a")
+
+	assert.NotEqual(t, coalescer.key(first), coalescer.key(second))
+	assert.Equal(t, coalescer.key(first), coalescer.key(third))
+}
+
+func TestCoalescer_Key_Credentials(t *testing.T) {
+	coalescer := newCoalescer(nil, []string{"authorization", "X-Tenant"}, defaultMaxBodyBytes)
+	first, _ := http.NewRequest(http.MethodGet, "http://rain.us/test", nil)
+	first.Header.Set("Cookie", "session=a")
+	second, _ := http.NewRequest(http.MethodGet, "http://rain.us/test", nil)
+	second.Header.Set("Cookie", "session=b")
+
+	assert.Equal(t, []string{"Authorization", "Cookie", "X-Tenant"}, coalescer.headers)
+	assert.NotEqual(t, coalescer.key(first), coalescer.key(second))
+}
+
+func TestClient_Get_CoalescingSeparatesCredentials(t *testing.T) {
+	var calls atomic.Int64
+	release := make(chan struct{})
+	requester := RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		calls.Add(1)
+		<-release
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(request.Header.Get("Authorization"))),
+		}, nil
+	})
+	client := NewHTTPClient(WithRequester(requester), WithCoalescing())
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 2)
+	for i, token := range []string{"Bearer alice", "Bearer bob"} {
+		wg.Add(1)
+		go func(i int, token string) {
+			defer wg.Done()
+			request, _ := client.NewRequestBuilder(context.Background()).
+				WithEndpoint("http://rain.us/me").
+				WithHeader("Authorization", token).
+				Build()
+			response, httpError := client.ExecuteRequest(request)
+			assert.Nil(t, httpError)
+			body, _ := io.ReadAll(response.Body)
+			bodies[i] = string(body)
+		}(i, token)
+	}
+
+	assert.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []string{"Bearer alice", "Bearer bob"}, bodies)
+}
+
+func TestCoalescer_CanCoalesce(t *testing.T) {
+	coalescer := newCoalescer(nil, nil, defaultMaxBodyBytes)
+	request := func(method string, accept string) *http.Request {
+		request, _ := http.NewRequest(method, "http://rain.us/events", nil)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		return request
+	}
+
+	assert.True(t, coalescer.canCoalesce(request(http.MethodGet, "application/json")))
+	assert.False(t, coalescer.canCoalesce(request(http.MethodPost, "")))
+	assert.False(t, coalescer.canCoalesce(request(http.MethodGet, "text/event-stream")))
+	assert.False(t, coalescer.canCoalesce(request(http.MethodGet, "application/x-ndjson")))
+}
+
+func TestClient_Get_CoalescingMaxBodyBytes(t *testing.T) {
+	requester := RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("0123456789"))}, nil
+	})
+	client := NewHTTPClient(WithRequester(requester), WithCoalescing(), WithMaxBodyBytes(4))
+
+	response, httpError := client.Get(context.Background(), "http://rain.us/users")
+
+	assert.Nil(t, response)
+	assert.Equal(t, "response body too large", httpError.Message)
+}
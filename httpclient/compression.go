@@ -0,0 +1,126 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ttanik/http-client/httperror"
+)
+
+// Compression configures request body compression.
+type Compression struct {
+	// Encoding is gzip, deflate or zstd.
+	Encoding string
+	// Threshold is the smallest body, in bytes, that gets compressed. Bodies
+	// of unknown size are never compressed.
+	Threshold int64
+}
+
+type compressor func(writer io.Writer) (io.WriteCloser, error)
+
+func newCompressor(encoding string) (compressor, bool) {
+	switch strings.ToLower(encoding) {
+	case "gzip":
+		return func(writer io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(writer), nil
+		}, true
+	case "deflate":
+		return func(writer io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(writer), nil
+		}, true
+	case "zstd":
+		return func(writer io.Writer) (io.WriteCloser, error) {
+			return zstd.NewWriter(writer, zstd.WithEncoderConcurrency(1))
+		}, true
+	}
+	return nil, false
+}
+
+func (compression *Compression) compress(body *requestBody) (*requestBody, *httperror.HTTPError) {
+	if body == nil {
+		return nil, nil
+	}
+
+	compress, ok := newCompressor(compression.Encoding)
+	if !ok {
+		return nil, &httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "unsupported request compression " + compression.Encoding,
+			Time:    time.Now(),
+		}
+	}
+
+	size, inMemory := body.size()
+	if size < compression.Threshold || size <= 0 {
+		return body, nil
+	}
+
+	if inMemory {
+		var compressed bytes.Buffer
+		if err := copyCompressed(&compressed, body.reader, compress); err != nil {
+			return nil, &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "error compressing request body",
+				Err:     err,
+				Time:    time.Now(),
+			}
+		}
+		return &requestBody{reader: bytes.NewReader(compressed.Bytes()), contentEncoding: compression.Encoding}, nil
+	}
+
+	compressedBody := &requestBody{
+		reader: newPipeBody(func(writer io.Writer) error {
+			return copyCompressed(writer, body.reader, compress)
+		}),
+		contentEncoding: compression.Encoding,
+	}
+	if body.getBody != nil {
+		compressedBody.getBody = func() (io.ReadCloser, error) {
+			original, err := body.getBody()
+			if err != nil {
+				return nil, err
+			}
+			return newPipeBody(func(writer io.Writer) error {
+				defer original.Close()
+				return copyCompressed(writer, original, compress)
+			}), nil
+		}
+	}
+	return compressedBody, nil
+}
+
+// size returns the body size when it is known up front and whether the body
+// is already held in memory.
+func (body *requestBody) size() (int64, bool) {
+	switch reader := body.reader.(type) {
+	case *bytes.Reader:
+		return int64(reader.Len()), true
+	case *strings.Reader:
+		return int64(reader.Len()), true
+	case *bytes.Buffer:
+		return int64(reader.Len()), true
+	}
+
+	if body.contentLength > 0 {
+		return body.contentLength, false
+	}
+	return -1, false
+}
+
+func copyCompressed(writer io.Writer, reader io.Reader, compress compressor) error {
+	compressedWriter, err := compress(writer)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(compressedWriter, reader); err != nil {
+		_ = compressedWriter.Close()
+		return err
+	}
+	return compressedWriter.Close()
+}
@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient/mocks"
+)
+
+func buildCompressed(t *testing.T, compression Compression, body interface{}) *http.Request {
+	request, httpError := NewRequestBuilder(context.Background(), RequestBuilderConfigs{
+		Marshaller:     new(mocks.Marshaller),
+		AcceptEncoding: "gzip, zstd",
+		Compression:    &compression,
+	}).
+		WithEndpoint("http://rain.us/test").
+		WithMethod(http.MethodPost).
+		WithBody(body).
+		Build()
+	assert.Nil(t, httpError)
+	return request
+}
+
+func gunzip(t *testing.T, body io.Reader) string {
+	reader, err := gzip.NewReader(body)
+	assert.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestRequestBuilder_Build_CompressesAboveThreshold(t *testing.T) {
+	content := strings.Repeat("compress me ", 100)
+	request := buildCompressed(t, Compression{Encoding: "gzip", Threshold: 100}, content)
+
+	assert.Equal(t, "gzip", request.Header.Get("Content-Encoding"))
+	assert.Equal(t, "gzip, zstd", request.Header.Get("Accept-Encoding"))
+	assert.Less(t, request.ContentLength, int64(len(content)))
+	assert.Equal(t, content, gunzip(t, request.Body))
+
+	replay, err := request.GetBody()
+	assert.NoError(t, err)
+	assert.Equal(t, content, gunzip(t, replay))
+}
+
+func TestRequestBuilder_Build_SkipsBelowThreshold(t *testing.T) {
+	request := buildCompressed(t, Compression{Encoding: "gzip", Threshold: 100}, "small")
+
+	assert.Empty(t, request.Header.Get("Content-Encoding"))
+	assert.Equal(t, "small", readBody(t, request.Body))
+}
+
+func TestRequestBuilder_Build_CompressesStreamedMultipart(t *testing.T) {
+	content := strings.Repeat("x", 1000)
+	request, httpError := NewRequestBuilder(context.Background(), RequestBuilderConfigs{
+		Compression: &Compression{Encoding: "gzip", Threshold: 100},
+	}).
+		WithEndpoint("http://rain.us/upload").
+		WithMethod(http.MethodPost).
+		WithMultipart(NewMultipart().AddFile("file", "x.txt", "text/plain", strings.NewReader(content))).
+		Build()
+	assert.Nil(t, httpError)
+
+	assert.Equal(t, "gzip", request.Header.Get("Content-Encoding"))
+	assert.Contains(t, gunzip(t, request.Body), content)
+	replay, err := request.GetBody()
+	assert.NoError(t, err)
+	assert.Contains(t, gunzip(t, replay), content)
+}
+
+func TestRequestBuilder_Build_UnsupportedCompression(t *testing.T) {
+	_, httpError := NewRequestBuilder(context.Background(), RequestBuilderConfigs{
+		Compression: &Compression{Encoding: "lzma"},
+	}).
+		WithEndpoint("http://rain.us/test").
+		WithBody("body").
+		Build()
+
+	assert.Equal(t, "unsupported request compression lzma", httpError.Message)
+}
+
+func TestRequestBuilder_Build_KeepsExplicitAcceptEncoding(t *testing.T) {
+	request, httpError := NewRequestBuilder(context.Background(), RequestBuilderConfigs{AcceptEncoding: "gzip"}).
+		WithEndpoint("http://rain.us/test").
+		WithHeaders(map[string]string{"Accept-Encoding": "identity"}).
+		Build()
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, "identity", request.Header.Get("Accept-Encoding"))
+}
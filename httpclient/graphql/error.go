@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ttanik/http-client/httperror"
+)
+
+// Location ...
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// ErrorDetail is an entry of the errors array of a response.
+type ErrorDetail struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	// Path holds field names and list indexes, e.g. ["user", "friends", 0].
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// PathString joins Path with dots, e.g. user.friends.0.
+func (detail ErrorDetail) PathString() string {
+	parts := make([]string, len(detail.Path))
+	for index, element := range detail.Path {
+		parts[index] = fmt.Sprint(element)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Error is a response with GraphQL errors. It unwraps to an HTTPError with
+// the response status, or 424 when the server answered 200.
+type Error struct {
+	Errors []ErrorDetail
+	// Partial is set when the response also has data, which has then been
+	// decoded into the target.
+	Partial   bool
+	HTTPError *httperror.HTTPError
+}
+
+func newError(status int, details []ErrorDetail, partial bool) *Error {
+	if status < http.StatusBadRequest {
+		status = http.StatusFailedDependency
+	}
+
+	return &Error{
+		Errors:  details,
+		Partial: partial,
+		HTTPError: &httperror.HTTPError{
+			Status:  status,
+			Message: "graphql errors",
+			Time:    time.Now(),
+		},
+	}
+}
+
+// Error ...
+func (graphqlError *Error) Error() string {
+	messages := make([]string, len(graphqlError.Errors))
+	for index, detail := range graphqlError.Errors {
+		messages[index] = detail.Message
+		if len(detail.Path) > 0 {
+			messages[index] += " at " + detail.PathString()
+		}
+	}
+
+	kind := "graphql errors"
+	if graphqlError.Partial {
+		kind = "partial graphql response"
+	}
+	return fmt.Sprintf("%s: %s", kind, strings.Join(messages, "; "))
+}
+
+// Unwrap ...
+func (graphqlError *Error) Unwrap() error {
+	return graphqlError.HTTPError
+}
+
+// IsPartial reports whether err is a response with both data and errors.
+func IsPartial(err error) bool {
+	var graphqlError *Error
+	return errors.As(err, &graphqlError) && graphqlError.Partial
+}
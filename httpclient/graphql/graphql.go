@@ -0,0 +1,173 @@
+// Package graphql sends GraphQL operations through an httpclient.Client.
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httperror"
+)
+
+const persistedQueryNotFound = "PersistedQueryNotFound"
+
+// Request is a GraphQL operation.
+type Request struct {
+	Query         string                 `json:"query,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Extensions    map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []ErrorDetail   `json:"errors"`
+}
+
+// Option configures a Client.
+type Option func(client *Client)
+
+// WithEndpoint sets the endpoint, relative to the base URL of the
+// httpclient.Client. Defaults to the base URL itself.
+func WithEndpoint(endpoint string) Option {
+	return func(client *Client) {
+		client.endpoint = endpoint
+	}
+}
+
+// WithPersistedQueries sends the SHA-256 hash of queries instead of their
+// text, following the automatic persisted queries protocol. Queries the
+// server does not know yet are sent again in full.
+func WithPersistedQueries() Option {
+	return func(client *Client) {
+		client.persistedQueries = true
+	}
+}
+
+// NewClient ...
+func NewClient(client *httpclient.Client, opts ...Option) *Client {
+	graphqlClient := &Client{client: client}
+	for _, opt := range opts {
+		opt(graphqlClient)
+	}
+	return graphqlClient
+}
+
+// Client ...
+type Client struct {
+	client           *httpclient.Client
+	endpoint         string
+	persistedQueries bool
+}
+
+// Execute sends request and decodes its data into target, which may be nil.
+//
+// Failures without a GraphQL response are *httperror.HTTPError. A response
+// with errors is an *Error wrapping an HTTPError; when it also has data, the
+// data is decoded and the error is Partial.
+func (client *Client) Execute(ctx context.Context, request Request, target interface{}) error {
+	if !client.persistedQueries || request.Query == "" {
+		return client.execute(ctx, request, target)
+	}
+
+	hash := sha256.Sum256([]byte(request.Query))
+	extensions := map[string]interface{}{}
+	for key, value := range request.Extensions {
+		extensions[key] = value
+	}
+	extensions["persistedQuery"] = map[string]interface{}{
+		"version":    1,
+		"sha256Hash": hex.EncodeToString(hash[:]),
+	}
+	request.Extensions = extensions
+
+	query := request.Query
+	request.Query = ""
+	err := client.execute(ctx, request, target)
+	if !isPersistedQueryNotFound(err) {
+		return err
+	}
+
+	request.Query = query
+	return client.execute(ctx, request, target)
+}
+
+// Query executes request and decodes its data into a T.
+func Query[T any](ctx context.Context, client *Client, request Request) (T, error) {
+	var data T
+	err := client.Execute(ctx, request, &data)
+	return data, err
+}
+
+func (client *Client) execute(ctx context.Context, request Request, target interface{}) error {
+	httpRequest, httpError := client.client.NewRequestBuilder(ctx).
+		WithMethod(http.MethodPost).
+		WithEndpoint(client.endpoint).
+		WithHeader("Content-Type", "application/json").
+		WithHeader("Accept", "application/json").
+		WithBody(request).
+		Build()
+	if httpError != nil {
+		return httpError
+	}
+
+	httpResponse, httpError := client.client.Do(httpRequest)
+	if httpError != nil {
+		return httpError
+	}
+	body, httpError := httpResponse.Bytes()
+	if httpError != nil {
+		return httpError
+	}
+
+	var graphqlResponse response
+	if err := json.Unmarshal(body, &graphqlResponse); err != nil {
+		if !httpResponse.IsSuccess() {
+			return &httperror.HTTPError{Status: httpResponse.StatusCode, Message: "unexpected response status", Time: time.Now()}
+		}
+		return &httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "error decoding graphql response",
+			Err:     err,
+			Time:    time.Now(),
+		}
+	}
+
+	hasData := len(graphqlResponse.Data) > 0 && string(graphqlResponse.Data) != "null"
+	if hasData && target != nil {
+		if err := json.Unmarshal(graphqlResponse.Data, target); err != nil {
+			return &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "error decoding graphql data",
+				Err:     err,
+				Time:    time.Now(),
+			}
+		}
+	}
+
+	if len(graphqlResponse.Errors) > 0 {
+		return newError(httpResponse.StatusCode, graphqlResponse.Errors, hasData)
+	}
+	if !httpResponse.IsSuccess() {
+		return &httperror.HTTPError{Status: httpResponse.StatusCode, Message: "unexpected response status", Time: time.Now()}
+	}
+	return nil
+}
+
+func isPersistedQueryNotFound(err error) bool {
+	var graphqlError *Error
+	if !errors.As(err, &graphqlError) {
+		return false
+	}
+	for _, detail := range graphqlError.Errors {
+		if detail.Message == persistedQueryNotFound || detail.Extensions["code"] == "PERSISTED_QUERY_NOT_FOUND" {
+			return true
+		}
+	}
+	return false
+}
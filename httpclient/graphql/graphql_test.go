@@ -0,0 +1,148 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httperror"
+)
+
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type userData struct {
+	User *user `json:"user"`
+}
+
+func newTestClient(t *testing.T, handler func(request Request) (int, string), opts ...Option) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/graphql", request.URL.Path)
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+		var graphqlRequest Request
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&graphqlRequest))
+		status, body := handler(graphqlRequest)
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(status)
+		_, _ = writer.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client := httpclient.NewHTTPClient(httpclient.WithBaseURL(server.URL))
+	return NewClient(client, append([]Option{WithEndpoint("/graphql")}, opts...)...)
+}
+
+func TestClient_Execute(t *testing.T) {
+	client := newTestClient(t, func(request Request) (int, string) {
+		assert.Equal(t, "query User($id: ID!) { user(id: $id) { id name } }", request.Query)
+		assert.Equal(t, map[string]interface{}{"id": "7"}, request.Variables)
+		assert.Equal(t, "User", request.OperationName)
+		return http.StatusOK, `{"data":{"user":{"id":"7","name":"ada"}}}`
+	})
+
+	data, err := Query[userData](context.Background(), client, Request{
+		Query:         "query User($id: ID!) { user(id: $id) { id name } }",
+		Variables:     map[string]interface{}{"id": "7"},
+		OperationName: "User",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &user{ID: "7", Name: "ada"}, data.User)
+}
+
+func TestClient_Execute_Errors(t *testing.T) {
+	client := newTestClient(t, func(request Request) (int, string) {
+		return http.StatusOK, `{"data":null,"errors":[{
+			"message":"not allowed",
+			"locations":[{"line":1,"column":3}],
+			"path":["user","friends",0],
+			"extensions":{"code":"FORBIDDEN"}
+		}]}`
+	})
+
+	var data userData
+	err := client.Execute(context.Background(), Request{Query: "{ user { friends { id } } }"}, &data)
+
+	var graphqlError *Error
+	assert.True(t, errors.As(err, &graphqlError))
+	assert.False(t, graphqlError.Partial)
+	assert.False(t, IsPartial(err))
+	assert.Len(t, graphqlError.Errors, 1)
+	assert.Equal(t, "user.friends.0", graphqlError.Errors[0].PathString())
+	assert.Equal(t, []Location{{Line: 1, Column: 3}}, graphqlError.Errors[0].Locations)
+	assert.Equal(t, "FORBIDDEN", graphqlError.Errors[0].Extensions["code"])
+	assert.Equal(t, "graphql errors: not allowed at user.friends.0", err.Error())
+
+	var httpError *httperror.HTTPError
+	assert.True(t, errors.As(err, &httpError))
+	assert.Equal(t, http.StatusFailedDependency, httpError.Status)
+	assert.Nil(t, data.User)
+}
+
+func TestClient_Execute_Partial(t *testing.T) {
+	client := newTestClient(t, func(request Request) (int, string) {
+		return http.StatusOK, `{"data":{"user":{"id":"7","name":"ada"}},"errors":[{"message":"friends unavailable","path":["user","friends"]}]}`
+	})
+
+	data, err := Query[userData](context.Background(), client, Request{Query: "{ user { id name friends { id } } }"})
+
+	assert.True(t, IsPartial(err))
+	assert.Equal(t, "partial graphql response: friends unavailable at user.friends", err.Error())
+	assert.Equal(t, "ada", data.User.Name)
+}
+
+func TestClient_Execute_ErrorStatus(t *testing.T) {
+	client := newTestClient(t, func(request Request) (int, string) {
+		if request.Query == "{ broken" {
+			return http.StatusBadRequest, `{"errors":[{"message":"syntax error"}]}`
+		}
+		return http.StatusBadGateway, `<html>bad gateway</html>`
+	})
+
+	err := client.Execute(context.Background(), Request{Query: "{ broken"}, nil)
+	var httpError *httperror.HTTPError
+	assert.True(t, errors.As(err, &httpError))
+	assert.Equal(t, http.StatusBadRequest, httpError.Status)
+	assert.Contains(t, err.Error(), "syntax error")
+
+	err = client.Execute(context.Background(), Request{Query: "{ user { id } }"}, nil)
+	assert.Equal(t, &httperror.HTTPError{Status: http.StatusBadGateway, Message: "unexpected response status", Time: err.(*httperror.HTTPError).Time}, err)
+}
+
+func TestClient_Execute_PersistedQueries(t *testing.T) {
+	query := "{ user { id } }"
+	hash := sha256.Sum256([]byte(query))
+	var requests []Request
+	client := newTestClient(t, func(request Request) (int, string) {
+		requests = append(requests, request)
+		if request.Query == "" && len(requests) == 1 {
+			return http.StatusOK, `{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`
+		}
+		return http.StatusOK, `{"data":{"user":{"id":"1"}}}`
+	}, WithPersistedQueries())
+
+	for range []int{1, 2} {
+		data, err := Query[userData](context.Background(), client, Request{Query: query})
+		assert.NoError(t, err)
+		assert.Equal(t, "1", data.User.ID)
+	}
+
+	assert.Len(t, requests, 3)
+	persistedQuery := map[string]interface{}{"version": float64(1), "sha256Hash": hex.EncodeToString(hash[:])}
+	assert.Empty(t, requests[0].Query)
+	assert.Equal(t, persistedQuery, requests[0].Extensions["persistedQuery"])
+	assert.Equal(t, query, requests[1].Query)
+	assert.Equal(t, persistedQuery, requests[1].Extensions["persistedQuery"])
+	assert.Empty(t, requests[2].Query)
+}
@@ -3,8 +3,11 @@ package httpclient
 import (
 	"context"
 	"net/http"
+	"time"
 
+	"github.com/ttanik/http-client/httpdecoder"
 	"github.com/ttanik/http-client/httperror"
+	"github.com/ttanik/http-client/httptiming"
 )
 
 // Marshaller ...
@@ -19,34 +22,76 @@ type Requester interface {
 
 // HTTPRequestBuilder ...
 type HTTPRequestBuilder interface {
+	WithContext(ctx context.Context) HTTPRequestBuilder
 	WithEndpoint(endpoint string) HTTPRequestBuilder
 	WithMethod(method string) HTTPRequestBuilder
 	WithHeaders(headers map[string]string) HTTPRequestBuilder
+	WithHeader(key string, value string) HTTPRequestBuilder
+	AddHeader(key string, value string) HTTPRequestBuilder
+	WithoutHeader(key string) HTTPRequestBuilder
+	WithPathParam(key string, value string) HTTPRequestBuilder
+	WithQueryParam(key string, value string) HTTPRequestBuilder
 	WithBody(body interface{}) HTTPRequestBuilder
+	WithMultipart(form *Multipart) HTTPRequestBuilder
+	WithIdempotencyKey(key string) HTTPRequestBuilder
+	Clone() HTTPRequestBuilder
 	Build() (*http.Request, *httperror.HTTPError)
 }
 
-// NewHTTPClient ...
-func NewHTTPClient(requester Requester, marshaller Marshaller) *Client {
-	builderConfigs := RequestBuilderConfigs{
-		Marshaller: marshaller,
+// NewHTTPClient builds a client from options. Without options it sends
+// JSON bodies through http.DefaultClient.
+func NewHTTPClient(opts ...Option) *Client {
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
 	}
 
-	return &Client{
-		requester:      requester,
-		builderConfigs: builderConfigs,
+	return newClient(options)
+}
+
+func newClient(options clientOptions) *Client {
+	options.defaults()
+
+	client := &Client{
+		options:   options,
+		requester: options.chain(),
 	}
+	if options.coalesce {
+		client.coalescer = newCoalescer(client.requester, options.coalesceHeaders, options.maxBodyBytes)
+	}
+	return client
 }
 
 // Client ...
 type Client struct {
-	requester      Requester
-	builderConfigs RequestBuilderConfigs
+	options   clientOptions
+	requester Requester
+	coalescer *coalescer
+}
+
+// With returns a child client with opts applied on top of the parent
+// configuration. The parent is left untouched.
+func (client *Client) With(opts ...Option) *Client {
+	options := client.options.clone()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return newClient(options)
+}
+
+// Decoder returns the decoder set with WithDecoder, or an
+// httpdecoder.Decoder by default.
+func (client *Client) Decoder() Decoder {
+	if client.options.decoder == nil {
+		return httpdecoder.NewHTTPDecoder()
+	}
+	return client.options.decoder
 }
 
 // Get ...
 func (client *Client) Get(ctx context.Context, endpoint string) (*http.Response, *httperror.HTTPError) {
-	request, err := NewRequestBuilder(ctx, client.builderConfigs).
+	request, err := NewRequestBuilder(ctx, client.options.builderConfigs).
 		WithEndpoint(endpoint).
 		WithMethod(http.MethodGet).
 		Build()
@@ -60,7 +105,7 @@ func (client *Client) Get(ctx context.Context, endpoint string) (*http.Response,
 // Patch ...
 func (client *Client) Patch(ctx context.Context, endpoint string, body interface{}, headers ...map[string]string) (*http.Response, *httperror.HTTPError) {
 	requestHeaders := makeHeader(headers...)
-	request, err := NewRequestBuilder(ctx, client.builderConfigs).
+	request, err := NewRequestBuilder(ctx, client.options.builderConfigs).
 		WithEndpoint(endpoint).
 		WithMethod(http.MethodPatch).
 		WithBody(body).
@@ -76,7 +121,7 @@ func (client *Client) Patch(ctx context.Context, endpoint string, body interface
 // Put ...
 func (client *Client) Put(ctx context.Context, endpoint string, body interface{}, headers ...map[string]string) (*http.Response, *httperror.HTTPError) {
 	requestHeaders := makeHeader(headers...)
-	request, err := NewRequestBuilder(ctx, client.builderConfigs).
+	request, err := NewRequestBuilder(ctx, client.options.builderConfigs).
 		WithEndpoint(endpoint).
 		WithMethod(http.MethodPut).
 		WithBody(body).
@@ -92,7 +137,7 @@ func (client *Client) Put(ctx context.Context, endpoint string, body interface{}
 // Post ...
 func (client *Client) Post(ctx context.Context, endpoint string, body interface{}, headers ...map[string]string) (*http.Response, *httperror.HTTPError) {
 	requestHeaders := makeHeader(headers...)
-	request, err := NewRequestBuilder(ctx, client.builderConfigs).
+	request, err := NewRequestBuilder(ctx, client.options.builderConfigs).
 		WithEndpoint(endpoint).
 		WithMethod(http.MethodPost).
 		WithBody(body).
@@ -107,12 +152,95 @@ func (client *Client) Post(ctx context.Context, endpoint string, body interface{
 
 // ExecuteRequest ...
 func (client *Client) ExecuteRequest(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	response, _, httpError := client.execute(request, client.options.timing)
+	return response, httpError
+}
+
+// Do executes request and wraps the response, see Response. Non-2xx
+// responses are not errors unless the error policy says so.
+func (client *Client) Do(request *http.Request) (*Response, *httperror.HTTPError) {
+	response, timing, httpError := client.execute(request, true)
+	if httpError != nil {
+		return nil, httpError
+	}
+	return newResponse(response, *timing, client.options), nil
+}
+
+func (client *Client) execute(request *http.Request, trace bool) (*http.Response, *httptiming.Timing, *httperror.HTTPError) {
+	request, release := client.options.withTimeout(request)
+	var recorder *httptiming.Recorder
+	if trace {
+		var ctx context.Context
+		ctx, recorder = httptiming.WithTrace(request.Context())
+		request = request.WithContext(ctx)
+	}
+	start := time.Now()
+
+	response, httpError := client.executeRequest(request)
+	if httpError == nil && client.options.errorPolicy != nil {
+		if httpError = client.options.errorPolicy(response); httpError != nil {
+			if response.Body != nil {
+				_ = response.Body.Close()
+			}
+			response = nil
+		}
+	}
+	release(response)
+	if client.options.leakDetector != nil {
+		client.options.leakDetector.track(request, response)
+	}
+
+	var timing *httptiming.Timing
+	if recorder != nil {
+		finished := recorder.Finish()
+		timing = &finished
+		if httpError != nil {
+			// Errors may be shared, e.g. between coalesced callers.
+			withTiming := *httpError
+			withTiming.Timing = timing
+			httpError = &withTiming
+		}
+		for _, observer := range client.options.timingObservers {
+			observer(request, finished, httpError)
+		}
+	}
+
+	if client.options.logger != nil {
+		client.log(request, response, httpError, time.Since(start), timing)
+	}
+	return response, timing, httpError
+}
+
+func (client *Client) executeRequest(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	if client.coalescer != nil && client.coalescer.canCoalesce(request) {
+		return client.coalescer.execute(request)
+	}
+
 	return client.requester.ExecuteRequest(request)
 }
 
+func (client *Client) log(
+	request *http.Request,
+	response *http.Response,
+	httpError *httperror.HTTPError,
+	duration time.Duration,
+	timing *httptiming.Timing,
+) {
+	details := ""
+	if timing != nil {
+		details = " (" + timing.String() + ")"
+	}
+
+	if httpError != nil {
+		client.options.logger.Printf("%s %s failed with %d %s in %s%s", request.Method, request.URL, httpError.Status, httpError.Message, duration, details)
+		return
+	}
+	client.options.logger.Printf("%s %s returned %d in %s%s", request.Method, request.URL, response.StatusCode, duration, details)
+}
+
 // NewRequestBuilder ...
 func (client *Client) NewRequestBuilder(ctx context.Context) HTTPRequestBuilder {
-	return NewRequestBuilder(ctx, client.builderConfigs)
+	return NewRequestBuilder(ctx, client.options.builderConfigs)
 }
 
 func makeHeader(headers ...map[string]string) map[string]string {
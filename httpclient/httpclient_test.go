@@ -15,7 +15,7 @@ func TestClient_ExecuteRequest(t *testing.T) {
 	requester.On("ExecuteRequest", mock.Anything).Return(&http.Response{}, nil)
 
 	marshaller := new(mocks.Marshaller)
-	client := NewHTTPClient(requester, marshaller)
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(marshaller))
 
 	response, err := client.ExecuteRequest(&http.Request{})
 
@@ -28,7 +28,7 @@ func TestClient_ExecuteRequest(t *testing.T) {
 func TestClient_Get_CreateRequestError(t *testing.T) {
 	requester := new(mocks.Requester)
 	marshaller := new(mocks.Marshaller)
-	client := NewHTTPClient(requester, marshaller)
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(marshaller))
 
 	response, httpError := client.Get(context.Background(), "\n / /")
 
@@ -44,7 +44,7 @@ func TestClient_Get(t *testing.T) {
 	requester.On("ExecuteRequest", mock.Anything).Return(&http.Response{}, nil)
 
 	marshaller := new(mocks.Marshaller)
-	client := NewHTTPClient(requester, marshaller)
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(marshaller))
 
 	getResponse, httpError := client.Get(context.Background(), "/test/get")
 
@@ -57,7 +57,7 @@ func TestClient_Get(t *testing.T) {
 func TestClient_Post_CreateRequestError(t *testing.T) {
 	requester := new(mocks.Requester)
 	marshaller := new(mocks.Marshaller)
-	client := NewHTTPClient(requester, marshaller)
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(marshaller))
 
 	response, httpError := client.Post(context.Background(), " \n / a", nil)
 
@@ -73,7 +73,7 @@ func TestClient_Post(t *testing.T) {
 	requester.On("ExecuteRequest", mock.Anything).Return(&http.Response{}, nil)
 
 	marshaller := new(mocks.Marshaller)
-	client := NewHTTPClient(requester, marshaller)
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(marshaller))
 
 	getResponse, httpError := client.Post(context.Background(), "/test/post", nil)
 
@@ -86,7 +86,7 @@ func TestClient_Post(t *testing.T) {
 func TestClient_Put_CreateRequestError(t *testing.T) {
 	requester := new(mocks.Requester)
 	marshaller := new(mocks.Marshaller)
-	client := NewHTTPClient(requester, marshaller)
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(marshaller))
 
 	response, httpError := client.Put(context.Background(), "  \n a", nil, nil)
 
@@ -102,7 +102,7 @@ func TestClient_Patch(t *testing.T) {
 	requester.On("ExecuteRequest", mock.Anything).Return(&http.Response{}, nil)
 
 	marshaller := new(mocks.Marshaller)
-	client := NewHTTPClient(requester, marshaller)
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(marshaller))
 
 	getResponse, httpError := client.Patch(context.Background(), "/test/patch", nil)
 
@@ -117,7 +117,7 @@ func TestClient_Put(t *testing.T) {
 	requester.On("ExecuteRequest", mock.Anything).Return(&http.Response{}, nil)
 
 	marshaller := new(mocks.Marshaller)
-	client := NewHTTPClient(requester, marshaller)
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(marshaller))
 
 	getResponse, httpError := client.Put(context.Background(), "/test/put", nil)
 
@@ -131,7 +131,7 @@ func TestClient_NewRequestBuilder(t *testing.T) {
 
 	requester := new(mocks.Requester)
 	marshaller := new(mocks.Marshaller)
-	client := NewHTTPClient(requester, marshaller)
+	client := NewHTTPClient(WithRequester(requester), WithMarshaller(marshaller))
 
 	newContextBuilder := client.NewRequestBuilder(context.Background())
 
@@ -3,72 +3,184 @@ package httpclient
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/ttanik/http-client/httperror"
 )
 
-// RequestBuilder ...
+// RequestBuilder builds requests. Every With* method returns a new builder
+// and leaves the receiver untouched, so a partially configured builder can be
+// kept as a template and shared between goroutines.
 type RequestBuilder struct {
+	// BaseURL is prepended to relative endpoints.
+	BaseURL    string
 	Endpoint   string
 	Method     string
 	Marshaller Marshaller
 	Body       interface{}
 	Ctx        context.Context
-	Headers    map[string]string
+	Headers    http.Header
+	// PathParams replace {name} placeholders in the endpoint.
+	PathParams  map[string]string
+	QueryParams url.Values
+	Multipart   *Multipart
+	// AcceptEncoding is sent unless the headers already set one.
+	AcceptEncoding string
+	Compression    *Compression
+	IdempotencyKey string
+	// IdempotencyKeys generates a key for POST and PATCH requests that do
+	// not carry one.
+	IdempotencyKeys IdempotencyKeyGenerator
 }
 
 // RequestBuilderConfigs ...
 type RequestBuilderConfigs struct {
-	Marshaller Marshaller
-	Headers    map[string]string
+	BaseURL         string
+	Marshaller      Marshaller
+	Headers         http.Header
+	AcceptEncoding  string
+	Compression     *Compression
+	IdempotencyKeys IdempotencyKeyGenerator
 }
 
 // NewRequestBuilder ...
 func NewRequestBuilder(ctx context.Context, configs RequestBuilderConfigs) HTTPRequestBuilder {
 	return &RequestBuilder{
-		Ctx:        ctx,
-		Marshaller: configs.Marshaller,
-		Headers:    getHeaders(configs.Headers),
+		Ctx:             ctx,
+		BaseURL:         configs.BaseURL,
+		Marshaller:      configs.Marshaller,
+		Headers:         getHeaders(configs.Headers),
+		AcceptEncoding:  configs.AcceptEncoding,
+		Compression:     configs.Compression,
+		IdempotencyKeys: configs.IdempotencyKeys,
 	}
 }
-func getHeaders(defaultHeaders map[string]string) map[string]string {
-	headers := map[string]string{}
+func getHeaders(defaultHeaders http.Header) http.Header {
+	headers := http.Header{}
 
-	for key, value := range defaultHeaders {
-		headers[key] = value
+	for key, values := range defaultHeaders {
+		for _, value := range values {
+			headers.Add(key, value)
+		}
 	}
 
 	return headers
 }
 
+// Clone returns an independent copy of the builder. The body and multipart
+// form are shared, since readers can only be sent once anyway.
+func (requestBuilder *RequestBuilder) Clone() HTTPRequestBuilder {
+	return requestBuilder.clone()
+}
+
+func (requestBuilder *RequestBuilder) clone() *RequestBuilder {
+	clone := *requestBuilder
+	clone.Headers = getHeaders(requestBuilder.Headers)
+	clone.PathParams = make(map[string]string, len(requestBuilder.PathParams))
+	for key, value := range requestBuilder.PathParams {
+		clone.PathParams[key] = value
+	}
+	clone.QueryParams = url.Values{}
+	for key, values := range requestBuilder.QueryParams {
+		clone.QueryParams[key] = append([]string(nil), values...)
+	}
+	return &clone
+}
+
+// WithContext ...
+func (requestBuilder *RequestBuilder) WithContext(ctx context.Context) HTTPRequestBuilder {
+	clone := requestBuilder.clone()
+	clone.Ctx = ctx
+	return clone
+}
+
 // WithEndpoint ...
 func (requestBuilder *RequestBuilder) WithEndpoint(endpoint string) HTTPRequestBuilder {
-	requestBuilder.Endpoint = endpoint
-	return requestBuilder
+	clone := requestBuilder.clone()
+	clone.Endpoint = endpoint
+	return clone
 }
 
 // WithMethod ...
 func (requestBuilder *RequestBuilder) WithMethod(method string) HTTPRequestBuilder {
-	requestBuilder.Method = method
-	return requestBuilder
+	clone := requestBuilder.clone()
+	clone.Method = method
+	return clone
+}
+
+// WithPathParam replaces {key} in the endpoint with the escaped value.
+func (requestBuilder *RequestBuilder) WithPathParam(key string, value string) HTTPRequestBuilder {
+	clone := requestBuilder.clone()
+	clone.PathParams[key] = value
+	return clone
+}
+
+// WithQueryParam adds a query parameter, keeping previous values of key.
+func (requestBuilder *RequestBuilder) WithQueryParam(key string, value string) HTTPRequestBuilder {
+	clone := requestBuilder.clone()
+	clone.QueryParams.Add(key, value)
+	return clone
 }
 
-// WithHeaders ...
+// WithHeaders sets the given headers, replacing any previous values.
 func (requestBuilder *RequestBuilder) WithHeaders(headers map[string]string) HTTPRequestBuilder {
+	clone := requestBuilder.clone()
 	for key, value := range headers {
-		requestBuilder.Headers[key] = value
+		clone.Headers.Set(key, value)
 	}
-	return requestBuilder
+	return clone
 }
 
-// WithBody ...
+// WithHeader sets a header, replacing any previous values.
+func (requestBuilder *RequestBuilder) WithHeader(key string, value string) HTTPRequestBuilder {
+	clone := requestBuilder.clone()
+	clone.Headers.Set(key, value)
+	return clone
+}
+
+// AddHeader adds a value to a header, keeping the previous ones.
+func (requestBuilder *RequestBuilder) AddHeader(key string, value string) HTTPRequestBuilder {
+	clone := requestBuilder.clone()
+	clone.Headers.Add(key, value)
+	return clone
+}
+
+// WithoutHeader removes a header, including one set by the client defaults.
+func (requestBuilder *RequestBuilder) WithoutHeader(key string) HTTPRequestBuilder {
+	clone := requestBuilder.clone()
+	clone.Headers.Del(key)
+	return clone
+}
+
+// WithBody sets the request body. []byte and string bodies are sent as is,
+// io.Reader and StreamEncoder bodies are streamed and anything else goes
+// through the Marshaller.
 func (requestBuilder *RequestBuilder) WithBody(body interface{}) HTTPRequestBuilder {
-	requestBuilder.Body = body
-	return requestBuilder
+	clone := requestBuilder.clone()
+	clone.Body = body
+	return clone
+}
+
+// WithMultipart sends form as a multipart/form-data body. It takes
+// precedence over WithBody.
+func (requestBuilder *RequestBuilder) WithMultipart(form *Multipart) HTTPRequestBuilder {
+	clone := requestBuilder.clone()
+	clone.Multipart = form
+	return clone
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header. Retries keep sending
+// the same key.
+func (requestBuilder *RequestBuilder) WithIdempotencyKey(key string) HTTPRequestBuilder {
+	clone := requestBuilder.clone()
+	clone.IdempotencyKey = key
+	return clone
 }
 
 // Build ...
@@ -78,9 +190,24 @@ func (requestBuilder *RequestBuilder) Build() (*http.Request, *httperror.HTTPErr
 		return nil, httpErr
 	}
 
-	url := requestBuilder.Endpoint
+	if requestBuilder.Compression != nil {
+		requestBody, httpErr = requestBuilder.Compression.compress(requestBody)
+		if httpErr != nil {
+			return nil, httpErr
+		}
+	}
 
-	request, err := http.NewRequestWithContext(requestBuilder.Ctx, requestBuilder.Method, url, requestBody)
+	endpoint, httpErr := requestBuilder.endpoint()
+	if httpErr != nil {
+		return nil, httpErr
+	}
+
+	var bodyReader io.Reader
+	if requestBody != nil {
+		bodyReader = requestBody.reader
+	}
+
+	request, err := http.NewRequestWithContext(requestBuilder.Ctx, requestBuilder.Method, endpoint, bodyReader)
 	if err != nil {
 		return nil, &httperror.HTTPError{
 			Status:  http.StatusInternalServerError,
@@ -91,27 +218,114 @@ func (requestBuilder *RequestBuilder) Build() (*http.Request, *httperror.HTTPErr
 	}
 
 	requestBuilder.applyHeaders(request)
+	requestBody.apply(request)
 
 	return request, nil
 }
 
-func (requestBuilder *RequestBuilder) getRequestBody() (io.Reader, *httperror.HTTPError) {
-	if requestBuilder.Body != nil {
-		jsonBody, httpError := requestBuilder.Marshaller.MarshalBody(requestBuilder.Body)
-		if httpError != nil {
-			return nil, httpError
+func (requestBuilder *RequestBuilder) getRequestBody() (*requestBody, *httperror.HTTPError) {
+	if requestBuilder.Multipart != nil {
+		return requestBuilder.Multipart.requestBody(), nil
+	}
+
+	switch body := requestBuilder.Body.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return &requestBody{reader: bytes.NewReader(body)}, nil
+	case string:
+		return &requestBody{reader: strings.NewReader(body)}, nil
+	case StreamEncoder:
+		return newStreamBody(body), nil
+	case func(writer io.Writer) error:
+		return newStreamBody(body), nil
+	case io.Reader:
+		readerBody, err := newReaderBody(body)
+		if err != nil {
+			return nil, &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "error reading request body",
+				Err:     err,
+				Time:    time.Now(),
+			}
 		}
-		return bytes.NewReader(jsonBody), nil
+		return readerBody, nil
 	}
 
-	return nil, nil
+	jsonBody, httpError := requestBuilder.Marshaller.MarshalBody(requestBuilder.Body)
+	if httpError != nil {
+		return nil, httpError
+	}
+	return &requestBody{reader: bytes.NewReader(jsonBody)}, nil
 }
 
 func (requestBuilder *RequestBuilder) applyHeaders(request *http.Request) {
-	for key, value := range requestBuilder.Headers {
-		request.Header.Set(key, value)
+	for key, values := range requestBuilder.Headers {
+		request.Header.Del(key)
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+
+	if requestBuilder.Multipart != nil {
+		request.Header.Set("Content-Type", requestBuilder.Multipart.ContentType())
+	}
+
+	if requestBuilder.AcceptEncoding != "" && request.Header.Get("Accept-Encoding") == "" {
+		request.Header.Set("Accept-Encoding", requestBuilder.AcceptEncoding)
+	}
+
+	if requestBuilder.IdempotencyKey != "" {
+		request.Header.Set(IdempotencyKeyHeader, requestBuilder.IdempotencyKey)
+	} else if requestBuilder.IdempotencyKeys != nil && requiresIdempotencyKey(request.Method) &&
+		request.Header.Get(IdempotencyKeyHeader) == "" {
+		request.Header.Set(IdempotencyKeyHeader, requestBuilder.IdempotencyKeys())
 	}
 
 	requestID := middleware.GetReqID(request.Context())
 	request.Header.Set(middleware.RequestIDHeader, requestID)
 }
+
+// endpoint resolves the endpoint against the base URL and fills in path and
+// query parameters.
+func (requestBuilder *RequestBuilder) endpoint() (string, *httperror.HTTPError) {
+	endpoint := requestBuilder.Endpoint
+	if len(requestBuilder.PathParams) > 0 {
+		for key, value := range requestBuilder.PathParams {
+			endpoint = strings.ReplaceAll(endpoint, "{"+key+"}", url.PathEscape(value))
+		}
+		if start := strings.Index(endpoint, "{"); start >= 0 && strings.Contains(endpoint[start:], "}") {
+			return "", &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "unresolved path parameter",
+				Err:     fmt.Errorf("endpoint %s", endpoint),
+				Time:    time.Now(),
+			}
+		}
+	}
+
+	endpoint = resolveEndpoint(requestBuilder.BaseURL, endpoint)
+	if len(requestBuilder.QueryParams) > 0 {
+		separator := "?"
+		if strings.Contains(endpoint, "?") {
+			separator = "&"
+		}
+		endpoint += separator + requestBuilder.QueryParams.Encode()
+	}
+	return endpoint, nil
+}
+
+// resolveEndpoint joins relative endpoints to baseURL, keeping the path of
+// baseURL unlike url.ResolveReference.
+func resolveEndpoint(baseURL string, endpoint string) string {
+	if baseURL == "" || strings.Contains(endpoint, "://") {
+		return endpoint
+	}
+	if endpoint == "" {
+		return baseURL
+	}
+	if strings.HasPrefix(endpoint, "?") {
+		return baseURL + endpoint
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(endpoint, "/")
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/go-chi/chi/middleware"
@@ -51,8 +53,8 @@ func TestRequestBuilder_Build(t *testing.T) {
 	marshaller := new(mocks.Marshaller)
 	marshaller.On("MarshalBody", 123).Return([]byte(`{"test":"test"}`), nil)
 
-	defaultHeaders := map[string]string{
-		"test": "test",
+	defaultHeaders := http.Header{
+		"Test": []string{"test"},
 	}
 
 	requestBuilder := &RequestBuilder{
@@ -100,20 +102,22 @@ func TestRequestBuilder_Build_WithRequestID(t *testing.T) {
 
 func TestRequestBuilder_WithBody(t *testing.T) {
 	requestBuilder := &RequestBuilder{}
-	requestBuilder.WithBody(123)
-	assert.Equal(t, 123, requestBuilder.Body)
+	withBody := requestBuilder.WithBody(123).(*RequestBuilder)
+	assert.Equal(t, 123, withBody.Body)
+	assert.Nil(t, requestBuilder.Body)
 }
 
 func TestRequestBuilder_WithEndpoint(t *testing.T) {
 	requestBuilder := &RequestBuilder{}
-	requestBuilder.WithEndpoint("/test")
-	assert.Equal(t, "/test", requestBuilder.Endpoint)
+	withEndpoint := requestBuilder.WithEndpoint("/test").(*RequestBuilder)
+	assert.Equal(t, "/test", withEndpoint.Endpoint)
+	assert.Empty(t, requestBuilder.Endpoint)
 }
 
 func TestRequestBuilder_WithHeader(t *testing.T) {
-	defaultHeaders := map[string]string{
-		"test":         "test",
-		"content-type": "ninjas",
+	defaultHeaders := http.Header{
+		"Test":         []string{"test"},
+		"Content-Type": []string{"ninjas"},
 	}
 	requestBuilder := &RequestBuilder{
 		Headers: defaultHeaders,
@@ -121,17 +125,133 @@ func TestRequestBuilder_WithHeader(t *testing.T) {
 	var headerMap = make(map[string]string)
 	headerMap["abc"] = "header-three"
 	headerMap["new-header"] = "the-new-header"
-	requestBuilder.WithHeaders(headerMap)
-	assert.Len(t, requestBuilder.Headers, 4)
+	withHeaders := requestBuilder.WithHeaders(headerMap).(*RequestBuilder)
+	assert.Len(t, withHeaders.Headers, 4)
+	assert.Len(t, requestBuilder.Headers, 2)
 
-	assert.Equal(t, "test", requestBuilder.Headers["test"])
-	assert.Equal(t, "ninjas", requestBuilder.Headers["content-type"])
-	assert.Equal(t, "header-three", requestBuilder.Headers["abc"])
-	assert.Equal(t, "the-new-header", requestBuilder.Headers["new-header"])
+	assert.Equal(t, "test", withHeaders.Headers.Get("test"))
+	assert.Equal(t, "ninjas", withHeaders.Headers.Get("content-type"))
+	assert.Equal(t, "header-three", withHeaders.Headers.Get("abc"))
+	assert.Equal(t, "the-new-header", withHeaders.Headers.Get("new-header"))
+}
+
+func TestRequestBuilder_MultiValueHeaders(t *testing.T) {
+	request, err := NewRequestBuilder(context.Background(), RequestBuilderConfigs{
+		Headers: http.Header{"Accept": []string{"application/json"}, "X-Default": []string{"on"}},
+	}).
+		WithEndpoint("http://rain.us/test").
+		AddHeader("Accept", "text/plain").
+		AddHeader("X-Tag", "one").
+		AddHeader("X-Tag", "two").
+		WithoutHeader("X-Default").
+		Build()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"application/json", "text/plain"}, request.Header.Values("Accept"))
+	assert.Equal(t, []string{"one", "two"}, request.Header.Values("X-Tag"))
+	assert.Empty(t, request.Header.Values("X-Default"))
+
+	request, err = NewRequestBuilder(context.Background(), RequestBuilderConfigs{}).
+		WithEndpoint("http://rain.us/test").
+		AddHeader("X-Tag", "one").
+		WithHeader("X-Tag", "only").
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"only"}, request.Header.Values("X-Tag"))
+}
+
+func TestRequestBuilder_Clone(t *testing.T) {
+	template := NewRequestBuilder(context.Background(), RequestBuilderConfigs{}).
+		WithEndpoint("http://rain.us/test").
+		WithHeader("X-Template", "yes")
+	clone := template.Clone().(*RequestBuilder)
+	clone.Headers.Set("X-Template", "changed")
+
+	request, err := template.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "yes", request.Header.Get("X-Template"))
+}
+
+func TestRequestBuilder_SharedTemplate(t *testing.T) {
+	template := NewRequestBuilder(context.Background(), RequestBuilderConfigs{
+		Headers: http.Header{"X-Default": []string{"on"}},
+	}).
+		WithEndpoint("http://rain.us/users").
+		WithHeader("Accept", "application/json")
+
+	var wait sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wait.Add(1)
+		go func(i int) {
+			defer wait.Done()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			id := strconv.Itoa(i)
+
+			request, err := template.
+				WithContext(ctx).
+				WithMethod(http.MethodPost).
+				WithHeader("X-Id", id).
+				AddHeader("Accept", "text/plain").
+				WithoutHeader("X-Default").
+				WithBody(id).
+				Build()
+
+			assert.Nil(t, err)
+			assert.Equal(t, id, request.Header.Get("X-Id"))
+			assert.Equal(t, []string{"application/json", "text/plain"}, request.Header.Values("Accept"))
+			assert.Equal(t, ctx, request.Context())
+			body, _ := io.ReadAll(request.Body)
+			assert.Equal(t, id, string(body))
+		}(i)
+	}
+	wait.Wait()
+
+	request, err := template.Build()
+	assert.Nil(t, err)
+	assert.Equal(t, http.MethodGet, request.Method)
+	assert.Equal(t, "on", request.Header.Get("X-Default"))
+	assert.Empty(t, request.Header.Get("X-Id"))
+	assert.Equal(t, []string{"application/json"}, request.Header.Values("Accept"))
 }
 
 func TestRequestBuilder_WithMethod(t *testing.T) {
 	requestBuilder := &RequestBuilder{}
-	requestBuilder.WithMethod(http.MethodPost)
-	assert.Equal(t, http.MethodPost, requestBuilder.Method)
+	withMethod := requestBuilder.WithMethod(http.MethodPost).(*RequestBuilder)
+	assert.Equal(t, http.MethodPost, withMethod.Method)
+	assert.Empty(t, requestBuilder.Method)
+}
+
+func TestRequestBuilder_WithPathParam(t *testing.T) {
+	request, err := NewRequestBuilder(context.Background(), RequestBuilderConfigs{}).
+		WithEndpoint("http://rain.us/teams/{team}/users/{id}").
+		WithPathParam("team", "red team/1").
+		WithPathParam("id", "7").
+		Build()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/teams/red%20team%2F1/users/7", request.URL.EscapedPath())
+}
+
+func TestRequestBuilder_WithPathParam_Unresolved(t *testing.T) {
+	request, err := NewRequestBuilder(context.Background(), RequestBuilderConfigs{}).
+		WithEndpoint("http://rain.us/teams/{team}/users/{id}").
+		WithPathParam("team", "red").
+		Build()
+
+	assert.Nil(t, request)
+	assert.Equal(t, http.StatusInternalServerError, err.Status)
+	assert.Equal(t, "unresolved path parameter", err.Message)
+}
+
+func TestRequestBuilder_WithQueryParam(t *testing.T) {
+	template := NewRequestBuilder(context.Background(), RequestBuilderConfigs{}).
+		WithEndpoint("http://rain.us/users?sort=name").
+		WithQueryParam("tag", "a b")
+	request, err := template.WithQueryParam("tag", "ops").Build()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a b", "ops"}, request.URL.Query()["tag"])
+	assert.Equal(t, "name", request.URL.Query().Get("sort"))
+	assert.Equal(t, []string{"a b"}, template.(*RequestBuilder).QueryParams["tag"])
 }
@@ -0,0 +1,65 @@
+package httpclient
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// IdempotencyKeyHeader ...
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyKeyGenerator returns a new idempotency key.
+type IdempotencyKeyGenerator func() string
+
+// UUIDv4 generates random RFC 4122 version 4 UUIDs.
+func UUIDv4() string {
+	var uuid [16]byte
+	_, _ = rand.Read(uuid[:])
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	var encoded [36]byte
+	hex.Encode(encoded[0:8], uuid[0:4])
+	encoded[8] = '-'
+	hex.Encode(encoded[9:13], uuid[4:6])
+	encoded[13] = '-'
+	hex.Encode(encoded[14:18], uuid[6:8])
+	encoded[18] = '-'
+	hex.Encode(encoded[19:23], uuid[8:10])
+	encoded[23] = '-'
+	hex.Encode(encoded[24:], uuid[10:])
+	return string(encoded[:])
+}
+
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID generates lexicographically sortable identifiers: a 48-bit
+// millisecond timestamp followed by 80 random bits, in Crockford base32.
+func ULID() string {
+	var id [16]byte
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], uint64(time.Now().UnixMilli()))
+	copy(id[:6], timestamp[2:])
+	_, _ = rand.Read(id[6:])
+
+	// 128 bits are encoded as 26 characters of 5 bits, the first holding
+	// only the top 3 bits.
+	var encoded [26]byte
+	high := binary.BigEndian.Uint64(id[:8])
+	low := binary.BigEndian.Uint64(id[8:])
+	for i := 25; i >= 0; i-- {
+		encoded[i] = crockfordAlphabet[low&0x1f]
+		low = low>>5 | high<<59
+		high >>= 5
+	}
+	return string(encoded[:])
+}
+
+// requiresIdempotencyKey reports whether method is unsafe and not
+// idempotent by definition, RFC 9110 section 9.2.
+func requiresIdempotencyKey(method string) bool {
+	return method == http.MethodPost || method == http.MethodPatch
+}
@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient/mocks"
+)
+
+func TestUUIDv4(t *testing.T) {
+	uuid := UUIDv4()
+
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), uuid)
+	assert.NotEqual(t, uuid, UUIDv4())
+}
+
+func TestULID(t *testing.T) {
+	first := ULID()
+	second := ULID()
+
+	assert.Regexp(t, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`), first)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, first[:6], second[:6])
+}
+
+func buildWithKeys(method string, configure func(builder HTTPRequestBuilder) HTTPRequestBuilder) *http.Request {
+	builder := NewRequestBuilder(context.Background(), RequestBuilderConfigs{
+		Marshaller:      new(mocks.Marshaller),
+		IdempotencyKeys: func() string { return "generated" },
+	}).
+		WithEndpoint("http://rain.us/test").
+		WithMethod(method)
+	request, _ := configure(builder).Build()
+	return request
+}
+
+func TestRequestBuilder_Build_GeneratesIdempotencyKey(t *testing.T) {
+	keep := func(builder HTTPRequestBuilder) HTTPRequestBuilder { return builder }
+
+	assert.Equal(t, "generated", buildWithKeys(http.MethodPost, keep).Header.Get(IdempotencyKeyHeader))
+	assert.Equal(t, "generated", buildWithKeys(http.MethodPatch, keep).Header.Get(IdempotencyKeyHeader))
+	assert.Empty(t, buildWithKeys(http.MethodGet, keep).Header.Get(IdempotencyKeyHeader))
+	assert.Empty(t, buildWithKeys(http.MethodPut, keep).Header.Get(IdempotencyKeyHeader))
+}
+
+func TestRequestBuilder_Build_KeepsCallerIdempotencyKey(t *testing.T) {
+	explicit := buildWithKeys(http.MethodPost, func(builder HTTPRequestBuilder) HTTPRequestBuilder {
+		return builder.WithIdempotencyKey("mine")
+	})
+	fromHeaders := buildWithKeys(http.MethodPost, func(builder HTTPRequestBuilder) HTTPRequestBuilder {
+		return builder.WithHeaders(map[string]string{IdempotencyKeyHeader: "header"})
+	})
+
+	assert.Equal(t, "mine", explicit.Header.Get(IdempotencyKeyHeader))
+	assert.Equal(t, "header", fromHeaders.Header.Get(IdempotencyKeyHeader))
+}
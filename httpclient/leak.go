@@ -0,0 +1,162 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const clientPackage = "github.com/ttanik/http-client/httpclient."
+
+// Leak is a response body that was never closed.
+type Leak struct {
+	Method  string
+	URL     string
+	Created time.Time
+	// Stack is where the response was handed out.
+	Stack string
+}
+
+// String ...
+func (leak Leak) String() string {
+	return fmt.Sprintf("%s %s created at %s is not closed\n%s", leak.Method, leak.URL, leak.Created.Format(time.RFC3339Nano), leak.Stack)
+}
+
+// LeakDetector tracks response bodies handed out by clients created with
+// WithLeakDetection. It is meant for debugging and tests: capturing a stack
+// trace for every response is not free.
+type LeakDetector struct {
+	mutex  sync.Mutex
+	nextID uint64
+	open   map[uint64]Leak
+	onLeak func(leak Leak)
+}
+
+// NewLeakDetector reports bodies that are garbage collected without being
+// closed to onLeak, which may be nil.
+func NewLeakDetector(onLeak func(leak Leak)) *LeakDetector {
+	return &LeakDetector{open: map[uint64]Leak{}, onLeak: onLeak}
+}
+
+// WithLeakDetection wraps the body of every response to report the ones that
+// are never closed.
+func WithLeakDetection(detector *LeakDetector) Option {
+	return func(options *clientOptions) {
+		options.leakDetector = detector
+	}
+}
+
+// Leaks lists the bodies that are not closed yet, oldest first.
+func (detector *LeakDetector) Leaks() []Leak {
+	detector.mutex.Lock()
+	defer detector.mutex.Unlock()
+
+	leaks := make([]Leak, 0, len(detector.open))
+	for _, leak := range detector.open {
+		leaks = append(leaks, leak)
+	}
+	sort.Slice(leaks, func(i, j int) bool { return leaks[i].Created.Before(leaks[j].Created) })
+	return leaks
+}
+
+// CheckLeaks returns an error describing every body that is not closed yet,
+// e.g. assert.NoError(t, detector.CheckLeaks()) at the end of a test.
+func (detector *LeakDetector) CheckLeaks() error {
+	leaks := detector.Leaks()
+	if len(leaks) == 0 {
+		return nil
+	}
+
+	descriptions := make([]string, len(leaks))
+	for index, leak := range leaks {
+		descriptions[index] = leak.String()
+	}
+	return fmt.Errorf("%d response bodies are not closed:\n%s", len(leaks), strings.Join(descriptions, "\n"))
+}
+
+func (detector *LeakDetector) track(request *http.Request, response *http.Response) {
+	// A 101 body is the upgraded connection, which callers may type-assert.
+	if response == nil || response.Body == nil || response.Body == http.NoBody ||
+		response.StatusCode == http.StatusSwitchingProtocols {
+		return
+	}
+
+	detector.mutex.Lock()
+	detector.nextID++
+	id := detector.nextID
+	detector.open[id] = Leak{
+		Method:  request.Method,
+		URL:     request.URL.String(),
+		Created: time.Now(),
+		Stack:   callerStack(),
+	}
+	detector.mutex.Unlock()
+
+	body := &leakTrackedBody{ReadCloser: response.Body, detector: detector, id: id}
+	// The detector only keeps the id, so an unclosed body can still be
+	// collected and reported.
+	runtime.SetFinalizer(body, (*leakTrackedBody).finalize)
+	response.Body = body
+}
+
+func (detector *LeakDetector) closed(id uint64) (Leak, bool) {
+	detector.mutex.Lock()
+	defer detector.mutex.Unlock()
+
+	leak, ok := detector.open[id]
+	delete(detector.open, id)
+	return leak, ok
+}
+
+type leakTrackedBody struct {
+	io.ReadCloser
+	detector *LeakDetector
+	id       uint64
+	once     sync.Once
+}
+
+// Close ...
+func (body *leakTrackedBody) Close() error {
+	body.once.Do(func() {
+		runtime.SetFinalizer(body, nil)
+		body.detector.closed(body.id)
+	})
+	return body.ReadCloser.Close()
+}
+
+func (body *leakTrackedBody) finalize() {
+	leak, ok := body.detector.closed(body.id)
+	if ok && body.detector.onLeak != nil {
+		body.detector.onLeak(leak)
+	}
+	_ = body.ReadCloser.Close()
+}
+
+// callerStack formats the stack above the Client methods.
+func callerStack() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+
+	var builder strings.Builder
+	skipping := true
+	for {
+		frame, more := frames.Next()
+		if skipping && strings.HasPrefix(frame.Function, clientPackage) && !strings.HasSuffix(frame.File, "_test.go") {
+			if !more {
+				break
+			}
+			continue
+		}
+		skipping = false
+		fmt.Fprintf(&builder, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return builder.String()
+}
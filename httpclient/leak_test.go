@@ -0,0 +1,148 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httperror"
+)
+
+func newLeakTestClient(detector *LeakDetector) *Client {
+	requester := RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{}`))}, nil
+	})
+	return NewHTTPClient(WithRequester(requester), WithLeakDetection(detector))
+}
+
+func TestLeakDetector_CheckLeaks(t *testing.T) {
+	detector := NewLeakDetector(nil)
+	client := newLeakTestClient(detector)
+
+	closed, _ := client.Get(context.Background(), "http://rain.us/closed")
+	leaked, _ := client.Get(context.Background(), "http://rain.us/leaked")
+	assert.NoError(t, closed.Body.Close())
+
+	err := detector.CheckLeaks()
+	assert.ErrorContains(t, err, "1 response bodies are not closed")
+	assert.ErrorContains(t, err, "GET http://rain.us/leaked")
+
+	leaks := detector.Leaks()
+	assert.Len(t, leaks, 1)
+	assert.True(t, strings.HasPrefix(leaks[0].Stack, "github.com/ttanik/http-client/httpclient.TestLeakDetector_CheckLeaks\n"), leaks[0].Stack)
+	assert.Contains(t, leaks[0].Stack, "leak_test.go:")
+
+	assert.NoError(t, leaked.Body.Close())
+	assert.NoError(t, leaked.Body.Close())
+	assert.NoError(t, detector.CheckLeaks())
+}
+
+func TestLeakDetector_Response(t *testing.T) {
+	detector := NewLeakDetector(nil)
+	client := newLeakTestClient(detector)
+	request, _ := client.NewRequestBuilder(context.Background()).WithEndpoint("http://rain.us").Build()
+
+	response, httpError := client.Do(request)
+	assert.Nil(t, httpError)
+	assert.Error(t, detector.CheckLeaks())
+
+	var target map[string]interface{}
+	assert.Nil(t, response.Decode(&target))
+	assert.NoError(t, detector.CheckLeaks())
+}
+
+type signalingBody struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (body *signalingBody) Close() error {
+	close(body.closed)
+	return nil
+}
+
+func TestLeakDetector_Finalizer(t *testing.T) {
+	leaks := make(chan Leak, 1)
+	detector := NewLeakDetector(func(leak Leak) { leaks <- leak })
+	body := &signalingBody{Reader: strings.NewReader(`{}`), closed: make(chan struct{})}
+	requester := RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+	})
+	client := NewHTTPClient(WithRequester(requester), WithLeakDetection(detector))
+
+	func() {
+		_, _ = client.Get(context.Background(), "http://rain.us/forgotten")
+	}()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case leak := <-leaks:
+			assert.Equal(t, "http://rain.us/forgotten", leak.URL)
+			assert.NoError(t, detector.CheckLeaks())
+			select {
+			case <-body.closed:
+			case <-deadline:
+				t.Fatal("leaked body was not closed")
+			}
+			return
+		case <-deadline:
+			t.Fatal("leak was not reported")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestLeakDetector_SwitchingProtocols(t *testing.T) {
+	detector := NewLeakDetector(nil)
+	body := io.NopCloser(strings.NewReader(""))
+	requester := RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		return &http.Response{StatusCode: http.StatusSwitchingProtocols, Body: body}, nil
+	})
+	client := NewHTTPClient(WithRequester(requester), WithLeakDetection(detector))
+
+	response, httpError := client.Get(context.Background(), "http://rain.us/socket")
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, body, response.Body)
+	assert.NoError(t, detector.CheckLeaks())
+}
+
+type countingBody struct {
+	io.Reader
+	closes atomic.Int32
+}
+
+func (body *countingBody) Close() error {
+	body.closes.Add(1)
+	return nil
+}
+
+func TestLeakDetector_CloseClearsFinalizer(t *testing.T) {
+	leaks := make(chan Leak, 1)
+	detector := NewLeakDetector(func(leak Leak) { leaks <- leak })
+	body := &countingBody{Reader: strings.NewReader(`{}`)}
+	requester := RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		return &http.Response{StatusCode: http.StatusOK, Body: body}, nil
+	})
+	client := NewHTTPClient(WithRequester(requester), WithLeakDetection(detector))
+
+	func() {
+		response, _ := client.Get(context.Background(), "http://rain.us/closed")
+		assert.NoError(t, response.Body.Close())
+	}()
+	for i := 0; i < 5; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Equal(t, int32(1), body.closes.Load())
+	assert.Empty(t, leaks)
+}
@@ -0,0 +1,221 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// MultipartProgress reports how many body bytes have been written so far.
+// total is -1 when the body size is unknown.
+type MultipartProgress func(written, total int64)
+
+// NewMultipart ...
+func NewMultipart() *Multipart {
+	return &Multipart{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+}
+
+// Multipart is a multipart/form-data body. Parts are streamed in order when
+// the request is sent; file contents are never buffered in memory.
+type Multipart struct {
+	boundary string
+	parts    []multipartPart
+	progress MultipartProgress
+}
+
+type multipartPart struct {
+	fieldName   string
+	fileName    string
+	contentType string
+	value       string
+	reader      io.Reader
+	start       int64
+	size        int64
+}
+
+// AddField ...
+func (form *Multipart) AddField(name, value string) *Multipart {
+	form.parts = append(form.parts, multipartPart{fieldName: name, value: value})
+	return form
+}
+
+// AddFile adds a file part. When reader is an io.Seeker the request gets a
+// Content-Length and can be replayed.
+func (form *Multipart) AddFile(fieldName, fileName, contentType string, reader io.Reader) *Multipart {
+	part := multipartPart{
+		fieldName:   fieldName,
+		fileName:    fileName,
+		contentType: contentType,
+		reader:      reader,
+		size:        -1,
+	}
+
+	if seeker, ok := reader.(io.Seeker); ok {
+		part.start, part.size = seekerSize(seeker)
+	}
+
+	form.parts = append(form.parts, part)
+	return form
+}
+
+// OnProgress ...
+func (form *Multipart) OnProgress(progress MultipartProgress) *Multipart {
+	form.progress = progress
+	return form
+}
+
+// ContentType returns the multipart/form-data content type with its boundary.
+func (form *Multipart) ContentType() string {
+	return "multipart/form-data; boundary=" + form.boundary
+}
+
+func (form *Multipart) requestBody() *requestBody {
+	contentLength, known := form.contentLength()
+	body := &requestBody{
+		reader: newPipeBody(form.encode),
+	}
+	if known {
+		body.contentLength = contentLength
+		body.getBody = func() (io.ReadCloser, error) {
+			if err := form.rewind(); err != nil {
+				return nil, err
+			}
+			return newPipeBody(form.encode), nil
+		}
+	}
+	return body
+}
+
+func (form *Multipart) encode(writer io.Writer) error {
+	total, known := form.contentLength()
+	if !known {
+		total = -1
+	}
+	if form.progress != nil {
+		writer = &progressWriter{writer: writer, total: total, progress: form.progress}
+	}
+
+	return form.write(writer, true)
+}
+
+func (form *Multipart) write(writer io.Writer, withContent bool) error {
+	multipartWriter := multipart.NewWriter(writer)
+	if err := multipartWriter.SetBoundary(form.boundary); err != nil {
+		return err
+	}
+
+	for _, part := range form.parts {
+		if part.reader == nil {
+			if err := multipartWriter.WriteField(part.fieldName, part.value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		partWriter, err := multipartWriter.CreatePart(part.header())
+		if err != nil {
+			return err
+		}
+		if !withContent {
+			continue
+		}
+		if _, err := io.Copy(partWriter, part.reader); err != nil {
+			return err
+		}
+	}
+
+	return multipartWriter.Close()
+}
+
+// contentLength is the size of the framing plus the size of every file. It is
+// only known when every file reader is seekable.
+func (form *Multipart) contentLength() (int64, bool) {
+	counter := &countingWriter{}
+	if err := form.write(counter, false); err != nil {
+		return 0, false
+	}
+
+	length := counter.written
+	for _, part := range form.parts {
+		if part.reader == nil {
+			continue
+		}
+		if part.size < 0 {
+			return 0, false
+		}
+		length += part.size
+	}
+	return length, true
+}
+
+func (form *Multipart) rewind() error {
+	for _, part := range form.parts {
+		if seeker, ok := part.reader.(io.Seeker); ok {
+			if _, err := seeker.Seek(part.start, io.SeekStart); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (part multipartPart) header() textproto.MIMEHeader {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(part.fieldName), escapeQuotes(part.fileName)))
+
+	contentType := part.contentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header.Set("Content-Type", contentType)
+	return header
+}
+
+func seekerSize(seeker io.Seeker) (int64, int64) {
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, -1
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, -1
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return 0, -1
+	}
+	return start, end - start
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(value string) string {
+	return quoteEscaper.Replace(value)
+}
+
+type countingWriter struct {
+	written int64
+}
+
+func (writer *countingWriter) Write(p []byte) (int, error) {
+	writer.written += int64(len(p))
+	return len(p), nil
+}
+
+type progressWriter struct {
+	writer   io.Writer
+	written  int64
+	total    int64
+	progress MultipartProgress
+}
+
+func (writer *progressWriter) Write(p []byte) (int, error) {
+	n, err := writer.writer.Write(p)
+	writer.written += int64(n)
+	writer.progress(writer.written, writer.total)
+	return n, err
+}
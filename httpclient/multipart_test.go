@@ -0,0 +1,79 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient/mocks"
+)
+
+func buildMultipart(t *testing.T, form *Multipart) *http.Request {
+	request, httpError := NewRequestBuilder(context.Background(), RequestBuilderConfigs{Marshaller: new(mocks.Marshaller)}).
+		WithEndpoint("http://rain.us/upload").
+		WithMethod(http.MethodPost).
+		WithMultipart(form).
+		Build()
+	assert.Nil(t, httpError)
+	return request
+}
+
+func readParts(t *testing.T, request *http.Request, body io.Reader) map[string][2]string {
+	mediaType, params, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/form-data", mediaType)
+
+	parts := map[string][2]string{}
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		assert.NoError(t, err)
+		content, _ := io.ReadAll(part)
+		parts[part.FormName()] = [2]string{part.FileName(), string(content)}
+	}
+}
+
+func TestRequestBuilder_Build_Multipart(t *testing.T) {
+	var progress []int64
+	form := NewMultipart().
+		AddField("description", "contract").
+		AddFile("document", "contract.pdf", "application/pdf", strings.NewReader("%PDF")).
+		OnProgress(func(written, total int64) {
+			progress = append(progress, written)
+			assert.Greater(t, total, int64(0))
+		})
+
+	request := buildMultipart(t, form)
+	body, err := io.ReadAll(request.Body)
+	assert.NoError(t, err)
+
+	assert.Equal(t, int64(len(body)), request.ContentLength)
+	assert.Equal(t, int64(len(body)), progress[len(progress)-1])
+	parts := readParts(t, request, strings.NewReader(string(body)))
+	assert.Equal(t, [2]string{"", "contract"}, parts["description"])
+	assert.Equal(t, [2]string{"contract.pdf", "%PDF"}, parts["document"])
+
+	replay, err := request.GetBody()
+	assert.NoError(t, err)
+	replayed, _ := io.ReadAll(replay)
+	assert.Equal(t, body, replayed)
+}
+
+func TestRequestBuilder_Build_MultipartUnknownLength(t *testing.T) {
+	form := NewMultipart().
+		AddFile("document", "stream.bin", "", io.MultiReader(strings.NewReader("streamed")))
+
+	request := buildMultipart(t, form)
+
+	assert.Nil(t, request.GetBody)
+	parts := readParts(t, request, request.Body)
+	assert.Equal(t, [2]string{"stream.bin", "streamed"}, parts["document"])
+}
@@ -0,0 +1,286 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httperror"
+	"github.com/ttanik/http-client/httpmarshal"
+	"github.com/ttanik/http-client/httprequester"
+	"github.com/ttanik/http-client/httptiming"
+)
+
+// Option configures a Client, see NewHTTPClient and Client.With.
+type Option func(options *clientOptions)
+
+// Decoder ...
+type Decoder interface {
+	DecodeErrorBody(ctx context.Context, response *http.Response) *httperror.HTTPError
+}
+
+// Middleware wraps the requester of a client.
+type Middleware func(next Requester) Requester
+
+// RequesterFunc ...
+type RequesterFunc func(request *http.Request) (*http.Response, *httperror.HTTPError)
+
+// ExecuteRequest ...
+func (requesterFunc RequesterFunc) ExecuteRequest(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	return requesterFunc(request)
+}
+
+// ErrorPolicy turns a response into an error, or returns nil to hand the
+// response to the caller. The client closes the body of rejected responses.
+type ErrorPolicy func(response *http.Response) *httperror.HTTPError
+
+// ErrorOnStatus rejects responses with a status of at least minStatus.
+func ErrorOnStatus(minStatus int) ErrorPolicy {
+	return func(response *http.Response) *httperror.HTTPError {
+		if response.StatusCode < minStatus {
+			return nil
+		}
+		return &httperror.HTTPError{
+			Status:  response.StatusCode,
+			Message: "unexpected response status",
+			Time:    time.Now(),
+		}
+	}
+}
+
+// TimingObserver receives the timing of every request of a client created
+// with WithTiming, e.g. to feed metrics. httpError is nil on success.
+type TimingObserver func(request *http.Request, timing httptiming.Timing, httpError *httperror.HTTPError)
+
+// Logger is satisfied by *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type clientOptions struct {
+	requester       Requester
+	decoder         Decoder
+	timeout         time.Duration
+	middlewares     []Middleware
+	errorPolicy     ErrorPolicy
+	logger          Logger
+	timing          bool
+	maxBodyBytes    int64
+	leakDetector    *LeakDetector
+	timingObservers []TimingObserver
+	builderConfigs  RequestBuilderConfigs
+	coalesce        bool
+	coalesceHeaders []string
+}
+
+// clone copies everything a child client could otherwise mutate in its
+// parent.
+func (options clientOptions) clone() clientOptions {
+	options.middlewares = append([]Middleware(nil), options.middlewares...)
+	options.coalesceHeaders = append([]string(nil), options.coalesceHeaders...)
+	options.timingObservers = append([]TimingObserver(nil), options.timingObservers...)
+	options.builderConfigs.Headers = getHeaders(options.builderConfigs.Headers)
+	return options
+}
+
+// WithRequester sets the requester that executes requests. Defaults to an
+// httprequester.HTTPRequester over http.DefaultClient.
+func WithRequester(requester Requester) Option {
+	return func(options *clientOptions) {
+		options.requester = requester
+	}
+}
+
+// WithMarshaller sets the request body marshaller. Defaults to JSON.
+func WithMarshaller(marshaller Marshaller) Option {
+	return func(options *clientOptions) {
+		options.builderConfigs.Marshaller = marshaller
+	}
+}
+
+// WithDecoder sets the decoder of the default requester. It has no effect
+// together with WithRequester.
+func WithDecoder(decoder Decoder) Option {
+	return func(options *clientOptions) {
+		options.decoder = decoder
+	}
+}
+
+// WithBaseURL resolves relative endpoints against baseURL.
+func WithBaseURL(baseURL string) Option {
+	return func(options *clientOptions) {
+		options.builderConfigs.BaseURL = baseURL
+	}
+}
+
+// WithDefaultHeader adds a header to every request.
+func WithDefaultHeader(key string, value string) Option {
+	return func(options *clientOptions) {
+		if options.builderConfigs.Headers == nil {
+			options.builderConfigs.Headers = http.Header{}
+		}
+		options.builderConfigs.Headers.Add(key, value)
+	}
+}
+
+// WithDefaultHeaders adds headers to every request.
+func WithDefaultHeaders(headers http.Header) Option {
+	return func(options *clientOptions) {
+		for key, values := range headers {
+			for _, value := range values {
+				WithDefaultHeader(key, value)(options)
+			}
+		}
+	}
+}
+
+// WithUserAgent ...
+func WithUserAgent(userAgent string) Option {
+	return func(options *clientOptions) {
+		if options.builderConfigs.Headers == nil {
+			options.builderConfigs.Headers = http.Header{}
+		}
+		options.builderConfigs.Headers.Set("User-Agent", userAgent)
+	}
+}
+
+// WithTimeout bounds every request, including reading its response body.
+// A shorter deadline on the request context still wins.
+func WithTimeout(timeout time.Duration) Option {
+	return func(options *clientOptions) {
+		options.timeout = timeout
+	}
+}
+
+// WithMiddleware wraps the requester. The first middleware is the
+// outermost one.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(options *clientOptions) {
+		options.middlewares = append(options.middlewares, middlewares...)
+	}
+}
+
+// WithErrorPolicy ...
+func WithErrorPolicy(policy ErrorPolicy) Option {
+	return func(options *clientOptions) {
+		options.errorPolicy = policy
+	}
+}
+
+// WithLogger logs the method, URL, outcome and duration of every request.
+func WithLogger(logger Logger) Option {
+	return func(options *clientOptions) {
+		options.logger = logger
+	}
+}
+
+// WithTiming traces requests with httptrace. The timing is then available
+// through httptiming.FromResponse, on the Timing of returned errors, in log
+// lines and to observers.
+func WithTiming(observers ...TimingObserver) Option {
+	return func(options *clientOptions) {
+		options.timing = true
+		options.timingObservers = append(options.timingObservers, observers...)
+	}
+}
+
+// WithMaxBodyBytes bounds the bodies read by Response, 10 MiB by default.
+func WithMaxBodyBytes(maxBytes int64) Option {
+	return func(options *clientOptions) {
+		options.maxBodyBytes = maxBytes
+	}
+}
+
+// WithCoalescing shares a single in-flight GET between concurrent identical
+// requests. Requests are identical when method, URL, credentials and the
+// given headers match; every caller gets its own copy of the body, read up to
+// the limit of WithMaxBodyBytes. Streaming requests, which accept an event
+// stream or NDJSON, are never coalesced.
+func WithCoalescing(headers ...string) Option {
+	return func(options *clientOptions) {
+		options.coalesce = true
+		options.coalesceHeaders = headers
+	}
+}
+
+// WithAcceptEncoding sets the Accept-Encoding header of every request. The
+// transport then no longer decompresses gzip on its own, so responses must be
+// decoded with a decoder that decompresses, such as httpdecoder.Decoder.
+func WithAcceptEncoding(acceptEncoding string) Option {
+	return func(options *clientOptions) {
+		options.builderConfigs.AcceptEncoding = acceptEncoding
+	}
+}
+
+// WithRequestCompression compresses request bodies above a size threshold.
+func WithRequestCompression(compression Compression) Option {
+	return func(options *clientOptions) {
+		options.builderConfigs.Compression = &compression
+	}
+}
+
+// WithIdempotencyKeys generates an Idempotency-Key for POST and PATCH
+// requests that do not set one, e.g. WithIdempotencyKeys(UUIDv4).
+func WithIdempotencyKeys(generator IdempotencyKeyGenerator) Option {
+	return func(options *clientOptions) {
+		options.builderConfigs.IdempotencyKeys = generator
+	}
+}
+
+func (options *clientOptions) chain() Requester {
+	requester := options.requester
+	if requester == nil {
+		decoder := options.decoder
+		if decoder == nil {
+			decoder = httpdecoder.NewHTTPDecoder()
+		}
+		requester = httprequester.NewHTTPRequester(http.DefaultClient, decoder)
+	}
+
+	for index := len(options.middlewares) - 1; index >= 0; index-- {
+		requester = options.middlewares[index](requester)
+	}
+	return requester
+}
+
+func (options *clientOptions) defaults() {
+	if options.maxBodyBytes <= 0 {
+		options.maxBodyBytes = defaultMaxBodyBytes
+	}
+	if options.builderConfigs.Marshaller == nil {
+		options.builderConfigs.Marshaller = httpmarshal.NewHTTPMarshal()
+	}
+}
+
+// withTimeout applies the client timeout to the request. The returned
+// release function hands the cancellation over to the response body.
+func (options *clientOptions) withTimeout(request *http.Request) (*http.Request, func(response *http.Response)) {
+	if options.timeout <= 0 {
+		return request, func(*http.Response) {}
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), options.timeout)
+	return request.WithContext(ctx), func(response *http.Response) {
+		if response == nil || response.Body == nil {
+			cancel()
+			return
+		}
+		response.Body = &cancelOnCloseBody{ReadCloser: response.Body, cancel: cancel}
+	}
+}
+
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// Close ...
+func (body *cancelOnCloseBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(body.cancel)
+	return err
+}
@@ -0,0 +1,267 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httperror"
+	"github.com/ttanik/http-client/httprequester"
+	"github.com/ttanik/http-client/httptiming"
+)
+
+func recordingRequester(requests *[]*http.Request) RequesterFunc {
+	return func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		*requests = append(*requests, request)
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	}
+}
+
+func TestNewHTTPClient_Defaults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/api/users", request.URL.Path)
+		assert.Equal(t, "rain/1.0", request.Header.Get("User-Agent"))
+		assert.Equal(t, []string{"core", "platform"}, request.Header.Values("X-Team"))
+		body, _ := io.ReadAll(request.Body)
+		_, _ = writer.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(
+		WithBaseURL(server.URL+"/api/"),
+		WithUserAgent("rain/1.0"),
+		WithDefaultHeaders(http.Header{"X-Team": []string{"core", "platform"}}),
+	)
+	response, httpError := client.Post(context.Background(), "/users", map[string]string{"name": "rain"})
+
+	assert.Nil(t, httpError)
+	body, _ := io.ReadAll(response.Body)
+	assert.JSONEq(t, `{"name":"rain"}`, string(body))
+}
+
+func TestClient_With(t *testing.T) {
+	var requests []*http.Request
+	parent := NewHTTPClient(WithRequester(recordingRequester(&requests)), WithDefaultHeader("X-Parent", "1"))
+	child := parent.With(WithBaseURL("http://child.rain.us"), WithDefaultHeader("X-Child", "2"))
+
+	_, httpError := parent.Get(context.Background(), "http://rain.us/users")
+	assert.Nil(t, httpError)
+	_, httpError = child.Get(context.Background(), "/users")
+	assert.Nil(t, httpError)
+
+	assert.Equal(t, "http://rain.us/users", requests[0].URL.String())
+	assert.Equal(t, "1", requests[0].Header.Get("X-Parent"))
+	assert.Empty(t, requests[0].Header.Get("X-Child"))
+	assert.Equal(t, "http://child.rain.us/users", requests[1].URL.String())
+	assert.Equal(t, "1", requests[1].Header.Get("X-Parent"))
+	assert.Equal(t, "2", requests[1].Header.Get("X-Child"))
+}
+
+func TestClient_Middleware(t *testing.T) {
+	var calls []string
+	middleware := func(name string) Middleware {
+		return func(next Requester) Requester {
+			return RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+				calls = append(calls, name)
+				return next.ExecuteRequest(request)
+			})
+		}
+	}
+	var requests []*http.Request
+	client := NewHTTPClient(WithRequester(recordingRequester(&requests)), WithMiddleware(middleware("first"), middleware("second")))
+
+	_, httpError := client.Get(context.Background(), "http://rain.us/users")
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, []string{"first", "second"}, calls)
+	assert.Len(t, requests, 1)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (body *closeRecorder) Close() error {
+	body.closed = true
+	return nil
+}
+
+func TestClient_ErrorPolicy(t *testing.T) {
+	body := &closeRecorder{Reader: strings.NewReader("missing")}
+	requester := RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: body}, nil
+	})
+	client := NewHTTPClient(WithRequester(requester), WithErrorPolicy(ErrorOnStatus(http.StatusBadRequest)))
+
+	response, httpError := client.Get(context.Background(), "http://rain.us/users")
+
+	assert.Nil(t, response)
+	assert.Equal(t, http.StatusNotFound, httpError.Status)
+	assert.Equal(t, "unexpected response status", httpError.Message)
+	assert.True(t, body.closed)
+}
+
+func TestClient_ErrorPolicy_NilBody(t *testing.T) {
+	requester := RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		return &http.Response{StatusCode: http.StatusNotFound}, nil
+	})
+	client := NewHTTPClient(WithRequester(requester), WithErrorPolicy(ErrorOnStatus(http.StatusBadRequest)))
+
+	response, httpError := client.Get(context.Background(), "http://rain.us/users")
+
+	assert.Nil(t, response)
+	assert.Equal(t, http.StatusNotFound, httpError.Status)
+}
+
+func TestClient_Timeout(t *testing.T) {
+	var requestCtx context.Context
+	requester := RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		requestCtx = request.Context()
+		if request.URL.Path == "/slow" {
+			<-request.Context().Done()
+			return nil, &httperror.HTTPError{Status: http.StatusGatewayTimeout, Message: "request timed out"}
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	})
+	client := NewHTTPClient(WithRequester(requester), WithTimeout(20*time.Millisecond))
+
+	_, httpError := client.Get(context.Background(), "http://rain.us/slow")
+	assert.Equal(t, http.StatusGatewayTimeout, httpError.Status)
+
+	response, httpError := client.Get(context.Background(), "http://rain.us/fast")
+	assert.Nil(t, httpError)
+	assert.NoError(t, requestCtx.Err())
+	assert.NoError(t, response.Body.Close())
+	assert.Error(t, requestCtx.Err())
+}
+
+func TestClient_Logger(t *testing.T) {
+	var output bytes.Buffer
+	var requests []*http.Request
+	client := NewHTTPClient(WithRequester(recordingRequester(&requests)), WithLogger(log.New(&output, "", 0)))
+
+	_, _ = client.Get(context.Background(), "http://rain.us/users")
+
+	assert.Regexp(t, `^GET http://rain.us/users returned 200 in \S+\n$`, output.String())
+}
+
+func TestClient_Timing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		if request.URL.Path == "/missing" {
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var output bytes.Buffer
+	var observed []httptiming.Timing
+	client := NewHTTPClient(
+		WithBaseURL(server.URL),
+		WithErrorPolicy(ErrorOnStatus(http.StatusBadRequest)),
+		WithLogger(log.New(&output, "", 0)),
+		WithTiming(func(request *http.Request, timing httptiming.Timing, httpError *httperror.HTTPError) {
+			observed = append(observed, timing)
+		}),
+	)
+
+	response, httpError := client.Get(context.Background(), "/users")
+	assert.Nil(t, httpError)
+	assert.NoError(t, response.Body.Close())
+	timing, ok := httptiming.FromResponse(response)
+	assert.True(t, ok)
+	assert.Equal(t, 1, timing.Attempts)
+	assert.GreaterOrEqual(t, timing.ServerProcessing, 10*time.Millisecond)
+	assert.Contains(t, output.String(), "ttfb ")
+
+	_, httpError = client.Get(context.Background(), "/missing")
+	assert.Equal(t, http.StatusNotFound, httpError.Status)
+	assert.NotNil(t, httpError.Timing)
+	assert.True(t, httpError.Timing.ConnReused)
+	assert.Len(t, observed, 2)
+	assert.Equal(t, timing, observed[0])
+}
+
+func TestClient_Timing_Hedging(t *testing.T) {
+	var calls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-request.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}))
+	defer server.Close()
+
+	requester := httprequester.NewHTTPRequester(&http.Client{}, httpdecoder.NewHTTPDecoder()).
+		WithHedging(httprequester.HedgePolicy{Delay: 20 * time.Millisecond})
+	client := NewHTTPClient(WithRequester(requester), WithBaseURL(server.URL), WithTiming())
+	request, _ := client.NewRequestBuilder(context.Background()).WithEndpoint("/users").Build()
+
+	response, httpError := client.Do(request)
+
+	assert.Nil(t, httpError)
+	assert.NoError(t, response.Close())
+	assert.Equal(t, 2, response.Attempts)
+	assert.Less(t, response.Timing.TimeToFirstByte, 500*time.Millisecond)
+	assert.Greater(t, response.Timing.TimeToFirstByte, time.Duration(0))
+}
+
+func TestClient_Timing_Coalescing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(WithBaseURL(server.URL), WithCoalescing(), WithTiming())
+	var wg sync.WaitGroup
+	timings := make([]httptiming.Timing, 2)
+	for index := range timings {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			response, httpError := client.Get(context.Background(), "/users")
+			assert.Nil(t, httpError)
+			assert.NoError(t, response.Body.Close())
+			timings[index], _ = httptiming.FromResponse(response)
+		}(index)
+	}
+	wg.Wait()
+
+	for _, timing := range timings {
+		assert.Equal(t, 1, timing.Attempts)
+		assert.GreaterOrEqual(t, timing.ServerProcessing, 20*time.Millisecond)
+	}
+}
+
+func TestClient_WithoutTiming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+	defer server.Close()
+
+	response, httpError := NewHTTPClient().Get(context.Background(), server.URL)
+
+	assert.Nil(t, httpError)
+	assert.NoError(t, response.Body.Close())
+	_, ok := httptiming.FromResponse(response)
+	assert.False(t, ok)
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	assert.Equal(t, "/users", resolveEndpoint("", "/users"))
+	assert.Equal(t, "http://rain.us/api/users", resolveEndpoint("http://rain.us/api", "/users"))
+	assert.Equal(t, "http://rain.us/api/users", resolveEndpoint("http://rain.us/api/", "users"))
+	assert.Equal(t, "http://rain.us/api?page=2", resolveEndpoint("http://rain.us/api", "?page=2"))
+	assert.Equal(t, "http://other.us/users", resolveEndpoint("http://rain.us/api", "http://other.us/users"))
+}
@@ -0,0 +1,216 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Page is what a PageStrategy gets to decide where the next page is.
+type Page struct {
+	// BaseEndpoint is the paginated endpoint including the page size param.
+	BaseEndpoint string
+	Endpoint     string
+	Index        int
+	Response     *http.Response
+	Body         []byte
+	Items        int
+	PageSize     int
+}
+
+// PageStrategy ...
+type PageStrategy interface {
+	// Next returns the endpoint of the page after page, or false if page was
+	// the last one.
+	Next(page Page) (string, bool, error)
+}
+
+// IndexedPageStrategy can compute the endpoint of any page up front, which
+// allows pages to be prefetched concurrently.
+type IndexedPageStrategy interface {
+	PageStrategy
+	PageEndpoint(baseEndpoint string, index int, pageSize int) (string, error)
+}
+
+// LinkHeaderStrategy follows RFC 5988 Link headers with rel="next".
+func LinkHeaderStrategy() PageStrategy {
+	return linkHeaderStrategy{}
+}
+
+type linkHeaderStrategy struct{}
+
+var linkPattern = regexp.MustCompile(`<([^>]*)>([^,]*)`)
+var relPattern = regexp.MustCompile(`(?i)\brel\s*=\s*"?([^";]*)"?`)
+
+func (strategy linkHeaderStrategy) Next(page Page) (string, bool, error) {
+	for _, header := range page.Response.Header.Values("Link") {
+		for _, match := range linkPattern.FindAllStringSubmatch(header, -1) {
+			rel := relPattern.FindStringSubmatch(match[2])
+			if rel == nil || !containsField(rel[1], "next") {
+				continue
+			}
+
+			return resolveReference(page.Endpoint, match[1])
+		}
+	}
+	return "", false, nil
+}
+
+// CursorStrategy reads the next cursor from the JSON body at Path (dotted,
+// e.g. "meta.next_cursor") and sends it in the Param query parameter. An
+// empty or missing cursor ends the pagination.
+func CursorStrategy(path, param string) PageStrategy {
+	return cursorStrategy{path: path, param: param}
+}
+
+type cursorStrategy struct {
+	path  string
+	param string
+}
+
+func (strategy cursorStrategy) Next(page Page) (string, bool, error) {
+	raw, err := lookupJSONPath(page.Body, strategy.path)
+	if err != nil || raw == nil {
+		return "", false, err
+	}
+
+	var cursor interface{}
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return "", false, err
+	}
+
+	var value string
+	switch typed := cursor.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		value = typed
+	case float64:
+		value = strconv.FormatFloat(typed, 'f', -1, 64)
+	default:
+		return "", false, fmt.Errorf("unsupported cursor %s", raw)
+	}
+	if value == "" {
+		return "", false, nil
+	}
+
+	endpoint, err := withQueryParam(page.Endpoint, strategy.param, value)
+	return endpoint, err == nil, err
+}
+
+// PageNumberStrategy sends the page number in Param, starting at first.
+func PageNumberStrategy(param string, first int) IndexedPageStrategy {
+	return pageNumberStrategy{param: param, first: first}
+}
+
+type pageNumberStrategy struct {
+	param string
+	first int
+}
+
+func (strategy pageNumberStrategy) PageEndpoint(baseEndpoint string, index int, pageSize int) (string, error) {
+	return withQueryParam(baseEndpoint, strategy.param, strconv.Itoa(strategy.first+index))
+}
+
+func (strategy pageNumberStrategy) Next(page Page) (string, bool, error) {
+	return nextIndexedPage(strategy, page)
+}
+
+// OffsetStrategy sends the offset of the first item in Param. It requires a
+// page size.
+func OffsetStrategy(param string) IndexedPageStrategy {
+	return offsetStrategy{param: param}
+}
+
+type offsetStrategy struct {
+	param string
+}
+
+func (strategy offsetStrategy) PageEndpoint(baseEndpoint string, index int, pageSize int) (string, error) {
+	if pageSize <= 0 {
+		return "", errors.New("offset pagination requires a page size")
+	}
+	return withQueryParam(baseEndpoint, strategy.param, strconv.Itoa(index*pageSize))
+}
+
+func (strategy offsetStrategy) Next(page Page) (string, bool, error) {
+	return nextIndexedPage(strategy, page)
+}
+
+// nextIndexedPage stops at the first empty or short page.
+func nextIndexedPage(strategy IndexedPageStrategy, page Page) (string, bool, error) {
+	if isLastIndexedPage(page) {
+		return "", false, nil
+	}
+
+	endpoint, err := strategy.PageEndpoint(page.BaseEndpoint, page.Index+1, page.PageSize)
+	return endpoint, err == nil, err
+}
+
+func isLastIndexedPage(page Page) bool {
+	return page.Items == 0 || (page.PageSize > 0 && page.Items < page.PageSize)
+}
+
+func lookupJSONPath(body []byte, path string) (json.RawMessage, error) {
+	raw := json.RawMessage(body)
+	if path == "" {
+		return raw, nil
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return nil, err
+		}
+
+		var ok bool
+		raw, ok = object[key]
+		if !ok {
+			return nil, nil
+		}
+	}
+
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return nil, nil
+	}
+	return raw, nil
+}
+
+func withQueryParam(endpoint, key, value string) (string, error) {
+	parsedURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := parsedURL.Query()
+	query.Set(key, value)
+	parsedURL.RawQuery = query.Encode()
+	return parsedURL.String(), nil
+}
+
+func resolveReference(base, reference string) (string, bool, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", false, err
+	}
+	referenceURL, err := url.Parse(strings.TrimSpace(reference))
+	if err != nil {
+		return "", false, err
+	}
+	return baseURL.ResolveReference(referenceURL).String(), true, nil
+}
+
+func containsField(value, field string) bool {
+	for _, candidate := range strings.Fields(value) {
+		if strings.EqualFold(candidate, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,285 @@
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ttanik/http-client/httperror"
+)
+
+const defaultMaxPages = 1000
+
+// PaginatorConfigs ...
+type PaginatorConfigs struct {
+	// Strategy defaults to LinkHeaderStrategy.
+	Strategy PageStrategy
+	// ItemsPath is the dotted JSON path of the items array. Empty means the
+	// body itself is the array.
+	ItemsPath string
+	// PageSize is sent in PageSizeParam when both are set. Indexed strategies
+	// also use it to detect the last page.
+	PageSize      int
+	PageSizeParam string
+	// MaxPages guards against endless pagination. Asking for more pages
+	// than this returns an error.
+	MaxPages int
+	// Prefetch is the number of pages fetched concurrently. It only applies
+	// to an IndexedPageStrategy.
+	Prefetch int
+	Headers  map[string]string
+}
+
+// NewPaginator ...
+func NewPaginator[T any](client *Client, endpoint string, configs PaginatorConfigs) *Paginator[T] {
+	if configs.Strategy == nil {
+		configs.Strategy = LinkHeaderStrategy()
+	}
+	if configs.MaxPages <= 0 {
+		configs.MaxPages = defaultMaxPages
+	}
+
+	paginator := &Paginator[T]{
+		client:       client,
+		configs:      configs,
+		baseEndpoint: endpoint,
+	}
+
+	if configs.PageSize > 0 && configs.PageSizeParam != "" {
+		baseEndpoint, err := withQueryParam(endpoint, configs.PageSizeParam, strconv.Itoa(configs.PageSize))
+		if err != nil {
+			paginator.fail(paginationError("invalid paginated endpoint", err))
+			return paginator
+		}
+		paginator.baseEndpoint = baseEndpoint
+	}
+
+	paginator.next = paginator.baseEndpoint
+	if indexed, ok := configs.Strategy.(IndexedPageStrategy); ok {
+		next, err := indexed.PageEndpoint(paginator.baseEndpoint, 0, configs.PageSize)
+		if err != nil {
+			paginator.fail(paginationError("invalid paginated endpoint", err))
+			return paginator
+		}
+		paginator.next = next
+	}
+
+	return paginator
+}
+
+// Paginator fetches pages lazily, one NextPage call at a time.
+type Paginator[T any] struct {
+	client       *Client
+	configs      PaginatorConfigs
+	baseEndpoint string
+
+	next    string
+	index   int
+	done    bool
+	err     *httperror.HTTPError
+	pending []*pageFetch[T]
+}
+
+type pageFetch[T any] struct {
+	index  int
+	result chan pageResult[T]
+	cancel context.CancelFunc
+}
+
+type pageResult[T any] struct {
+	items []T
+	page  Page
+	err   *httperror.HTTPError
+}
+
+// NextPage returns the items of the next page, or false once there are no
+// more pages.
+func (paginator *Paginator[T]) NextPage(ctx context.Context) ([]T, bool, *httperror.HTTPError) {
+	if paginator.err != nil {
+		err := paginator.err
+		paginator.err = nil
+		return nil, false, err
+	}
+	if paginator.done {
+		return nil, false, nil
+	}
+
+	if indexed, ok := paginator.configs.Strategy.(IndexedPageStrategy); ok && paginator.configs.Prefetch > 1 {
+		return paginator.nextPrefetched(ctx, indexed)
+	}
+
+	result := paginator.fetch(ctx, paginator.next, paginator.index)
+	if result.err != nil {
+		paginator.done = true
+		return nil, false, result.err
+	}
+
+	next, more, err := paginator.configs.Strategy.Next(result.page)
+	if err != nil {
+		paginator.done = true
+		return nil, false, paginationError("error finding next page", err)
+	}
+
+	paginator.index++
+	paginator.next = next
+	paginator.done = !more
+	if more && paginator.index >= paginator.configs.MaxPages {
+		paginator.fail(paginationError("pagination exceeded max pages", nil))
+	}
+
+	return result.items, true, nil
+}
+
+// Each calls handle for every item of every page.
+func (paginator *Paginator[T]) Each(ctx context.Context, handle func(item T) error) *httperror.HTTPError {
+	defer paginator.Stop()
+
+	for {
+		items, ok, httpError := paginator.NextPage(ctx)
+		if httpError != nil {
+			return httpError
+		}
+		if !ok {
+			return nil
+		}
+
+		for _, item := range items {
+			if err := handle(item); err != nil {
+				return paginationError("error handling page item", err)
+			}
+		}
+	}
+}
+
+// All collects every item of every page.
+func (paginator *Paginator[T]) All(ctx context.Context) ([]T, *httperror.HTTPError) {
+	var all []T
+	httpError := paginator.Each(ctx, func(item T) error {
+		all = append(all, item)
+		return nil
+	})
+	return all, httpError
+}
+
+// Stop cancels prefetched pages that have not been consumed.
+func (paginator *Paginator[T]) Stop() {
+	paginator.done = true
+	for _, fetch := range paginator.pending {
+		fetch.cancel()
+	}
+	paginator.pending = nil
+}
+
+func (paginator *Paginator[T]) nextPrefetched(ctx context.Context, strategy IndexedPageStrategy) ([]T, bool, *httperror.HTTPError) {
+	scheduled := paginator.index + len(paginator.pending)
+	for len(paginator.pending) < paginator.configs.Prefetch && scheduled < paginator.configs.MaxPages {
+		endpoint, err := strategy.PageEndpoint(paginator.baseEndpoint, scheduled, paginator.configs.PageSize)
+		if err != nil {
+			paginator.Stop()
+			return nil, false, paginationError("invalid paginated endpoint", err)
+		}
+
+		fetchCtx, cancel := context.WithCancel(ctx)
+		fetch := &pageFetch[T]{index: scheduled, result: make(chan pageResult[T], 1), cancel: cancel}
+		go func() {
+			fetch.result <- paginator.fetch(fetchCtx, endpoint, fetch.index)
+		}()
+
+		paginator.pending = append(paginator.pending, fetch)
+		scheduled++
+	}
+
+	fetch := paginator.pending[0]
+	paginator.pending = paginator.pending[1:]
+	result := <-fetch.result
+	fetch.cancel()
+
+	if result.err != nil {
+		paginator.Stop()
+		return nil, false, result.err
+	}
+
+	paginator.index++
+	if isLastIndexedPage(result.page) {
+		paginator.Stop()
+	} else if paginator.index >= paginator.configs.MaxPages {
+		paginator.Stop()
+		paginator.fail(paginationError("pagination exceeded max pages", nil))
+	}
+
+	return result.items, true, nil
+}
+
+func (paginator *Paginator[T]) fetch(ctx context.Context, endpoint string, index int) pageResult[T] {
+	request, httpError := paginator.client.NewRequestBuilder(ctx).
+		WithEndpoint(endpoint).
+		WithMethod(http.MethodGet).
+		WithHeaders(paginator.configs.Headers).
+		Build()
+	if httpError != nil {
+		return pageResult[T]{err: httpError}
+	}
+
+	response, httpError := paginator.client.ExecuteRequest(request)
+	if httpError != nil {
+		return pageResult[T]{err: httpError}
+	}
+
+	body, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return pageResult[T]{err: paginationError("error reading page", err)}
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return pageResult[T]{err: &httperror.HTTPError{
+			Status:  response.StatusCode,
+			Message: "error fetching page",
+			Time:    time.Now(),
+		}}
+	}
+
+	var items []T
+	raw, err := lookupJSONPath(body, paginator.configs.ItemsPath)
+	if err == nil && raw != nil {
+		err = json.Unmarshal(raw, &items)
+	}
+	if err != nil {
+		return pageResult[T]{err: &httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "error decoding page",
+			Err:     err,
+			Time:    time.Now(),
+		}}
+	}
+
+	return pageResult[T]{
+		items: items,
+		page: Page{
+			BaseEndpoint: paginator.baseEndpoint,
+			Endpoint:     endpoint,
+			Index:        index,
+			Response:     response,
+			Body:         body,
+			Items:        len(items),
+			PageSize:     paginator.configs.PageSize,
+		},
+	}
+}
+
+func (paginator *Paginator[T]) fail(httpError *httperror.HTTPError) {
+	paginator.done = true
+	paginator.err = httpError
+}
+
+func paginationError(message string, err error) *httperror.HTTPError {
+	return &httperror.HTTPError{
+		Status:  http.StatusInternalServerError,
+		Message: message,
+		Err:     err,
+		Time:    time.Now(),
+	}
+}
@@ -0,0 +1,137 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient/mocks"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httprequester"
+)
+
+func newPaginationClient() *Client {
+	requester := httprequester.NewHTTPRequester(http.DefaultClient, httpdecoder.NewHTTPDecoder())
+	return NewHTTPClient(WithRequester(requester), WithMarshaller(new(mocks.Marshaller)))
+}
+
+func TestPaginator_LinkHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		page, _ := strconv.Atoi(request.URL.Query().Get("page"))
+		if page < 2 {
+			writer.Header().Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next", </items?page=0>; rel="first"`, page+1))
+		}
+		fmt.Fprintf(writer, `[%d]`, page)
+	}))
+	defer server.Close()
+
+	items, httpError := NewPaginator[int](newPaginationClient(), server.URL+"/items?page=0", PaginatorConfigs{}).
+		All(context.Background())
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, []int{0, 1, 2}, items)
+}
+
+func TestPaginator_Cursor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "2", request.URL.Query().Get("limit"))
+		switch request.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprint(writer, `{"data":["a","b"],"meta":{"next":"c1"}}`)
+		case "c1":
+			fmt.Fprint(writer, `{"data":["c"],"meta":{"next":null}}`)
+		}
+	}))
+	defer server.Close()
+
+	items, httpError := NewPaginator[string](newPaginationClient(), server.URL, PaginatorConfigs{
+		Strategy:      CursorStrategy("meta.next", "cursor"),
+		ItemsPath:     "data",
+		PageSize:      2,
+		PageSizeParam: "limit",
+	}).All(context.Background())
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, []string{"a", "b", "c"}, items)
+}
+
+func TestPaginator_Offset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		offset, _ := strconv.Atoi(request.URL.Query().Get("offset"))
+		if offset >= 4 {
+			fmt.Fprint(writer, `[4]`)
+			return
+		}
+		fmt.Fprintf(writer, `[%d,%d]`, offset, offset+1)
+	}))
+	defer server.Close()
+
+	paginator := NewPaginator[int](newPaginationClient(), server.URL, PaginatorConfigs{
+		Strategy: OffsetStrategy("offset"),
+		PageSize: 2,
+	})
+
+	first, ok, httpError := paginator.NextPage(context.Background())
+	assert.True(t, ok)
+	assert.Nil(t, httpError)
+	assert.Equal(t, []int{0, 1}, first)
+
+	rest, httpError := paginator.All(context.Background())
+	assert.Nil(t, httpError)
+	assert.Equal(t, []int{2, 3, 4}, rest)
+}
+
+func TestPaginator_PageNumberPrefetch(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+		page, _ := strconv.Atoi(request.URL.Query().Get("page"))
+		if page > 5 {
+			fmt.Fprint(writer, `[]`)
+			return
+		}
+		fmt.Fprintf(writer, `[%d]`, page)
+	}))
+	defer server.Close()
+
+	items, httpError := NewPaginator[int](newPaginationClient(), server.URL, PaginatorConfigs{
+		Strategy: PageNumberStrategy("page", 1),
+		PageSize: 1,
+		Prefetch: 3,
+	}).All(context.Background())
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, items)
+	assert.LessOrEqual(t, requests.Load(), int64(9))
+}
+
+func TestPaginator_MaxPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Link", `<?again>; rel="next"`)
+		fmt.Fprint(writer, `[1]`)
+	}))
+	defer server.Close()
+
+	items, httpError := NewPaginator[int](newPaginationClient(), server.URL, PaginatorConfigs{MaxPages: 3}).
+		All(context.Background())
+
+	assert.Equal(t, []int{1, 1, 1}, items)
+	assert.Equal(t, "pagination exceeded max pages", httpError.Message)
+}
+
+func TestPaginator_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, httpError := NewPaginator[int](newPaginationClient(), server.URL, PaginatorConfigs{}).All(context.Background())
+
+	assert.Equal(t, http.StatusNotFound, httpError.Status)
+	assert.Equal(t, "error fetching page", httpError.Message)
+}
@@ -0,0 +1,136 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// StreamEncoder writes a request body straight to the connection. It may be
+// called again when the request has to be replayed (redirects, retries,
+// hedging), so it must produce the same bytes every time.
+type StreamEncoder func(writer io.Writer) error
+
+// JSONStream encodes body as JSON while it is being sent instead of
+// marshalling it into memory first.
+func JSONStream(body interface{}) StreamEncoder {
+	return func(writer io.Writer) error {
+		return json.NewEncoder(writer).Encode(body)
+	}
+}
+
+type requestBody struct {
+	reader          io.Reader
+	contentLength   int64
+	getBody         func() (io.ReadCloser, error)
+	contentEncoding string
+}
+
+// apply sets what http.NewRequest cannot infer from the reader on its own.
+func (body *requestBody) apply(request *http.Request) {
+	if body == nil {
+		return
+	}
+	if body.contentLength > 0 {
+		request.ContentLength = body.contentLength
+	}
+	if body.getBody != nil {
+		request.GetBody = body.getBody
+	}
+	if body.contentEncoding != "" {
+		request.Header.Set("Content-Encoding", body.contentEncoding)
+	}
+}
+
+func newStreamBody(encoder StreamEncoder) *requestBody {
+	return &requestBody{
+		reader: newPipeBody(encoder),
+		getBody: func() (io.ReadCloser, error) {
+			return newPipeBody(encoder), nil
+		},
+	}
+}
+
+// newReaderBody keeps plain readers streaming. Seekable readers also get a
+// length and can be replayed.
+func newReaderBody(reader io.Reader) (*requestBody, error) {
+	switch reader.(type) {
+	case *bytes.Buffer, *bytes.Reader, *strings.Reader:
+		// http.NewRequest already sets ContentLength and GetBody for these.
+		return &requestBody{reader: reader}, nil
+	}
+
+	seeker, ok := reader.(io.ReadSeeker)
+	if !ok {
+		return &requestBody{reader: reader}, nil
+	}
+
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	size := end - start
+
+	if readerAt, ok := reader.(io.ReaderAt); ok {
+		return &requestBody{
+			reader:        io.NewSectionReader(readerAt, start, size),
+			contentLength: size,
+			getBody: func() (io.ReadCloser, error) {
+				return io.NopCloser(io.NewSectionReader(readerAt, start, size)), nil
+			},
+		}, nil
+	}
+
+	return &requestBody{
+		reader:        seeker,
+		contentLength: size,
+		getBody: func() (io.ReadCloser, error) {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+			return io.NopCloser(seeker), nil
+		},
+	}, nil
+}
+
+// pipeBody runs its encoder on the first Read, so a request that is never
+// sent does not leave a goroutine blocked on the pipe.
+type pipeBody struct {
+	encoder StreamEncoder
+	once    sync.Once
+	reader  *io.PipeReader
+	writer  *io.PipeWriter
+}
+
+func newPipeBody(encoder StreamEncoder) *pipeBody {
+	reader, writer := io.Pipe()
+	return &pipeBody{encoder: encoder, reader: reader, writer: writer}
+}
+
+func (body *pipeBody) start() {
+	go func() {
+		_ = body.writer.CloseWithError(body.encoder(body.writer))
+	}()
+}
+
+// Read ...
+func (body *pipeBody) Read(p []byte) (int, error) {
+	body.once.Do(body.start)
+	return body.reader.Read(p)
+}
+
+// Close ...
+func (body *pipeBody) Close() error {
+	body.once.Do(func() {})
+	return body.reader.Close()
+}
@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient/mocks"
+)
+
+func buildWithBody(t *testing.T, body interface{}) *http.Request {
+	marshaller := new(mocks.Marshaller)
+	request, httpError := NewRequestBuilder(context.Background(), RequestBuilderConfigs{Marshaller: marshaller}).
+		WithEndpoint("http://rain.us/test").
+		WithMethod(http.MethodPost).
+		WithBody(body).
+		Build()
+	assert.Nil(t, httpError)
+	marshaller.AssertNotCalled(t, "MarshalBody")
+	return request
+}
+
+func readBody(t *testing.T, body io.Reader) string {
+	content, err := io.ReadAll(body)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestRequestBuilder_Build_RawBodies(t *testing.T) {
+	for _, body := range []interface{}{[]byte(`{"raw":true}`), `{"raw":true}`} {
+		request := buildWithBody(t, body)
+
+		assert.Equal(t, `{"raw":true}`, readBody(t, request.Body))
+		assert.Equal(t, int64(12), request.ContentLength)
+		assert.NotNil(t, request.GetBody)
+	}
+}
+
+func TestRequestBuilder_Build_StreamEncoder(t *testing.T) {
+	request := buildWithBody(t, JSONStream(map[string]string{"name": "test"}))
+
+	assert.JSONEq(t, `{"name":"test"}`, readBody(t, request.Body))
+	replay, err := request.GetBody()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"test"}`, readBody(t, replay))
+}
+
+func TestRequestBuilder_Build_StreamEncoderError(t *testing.T) {
+	request := buildWithBody(t, StreamEncoder(func(writer io.Writer) error {
+		_, _ = writer.Write([]byte("partial"))
+		return errors.New("encoder failed")
+	}))
+
+	_, err := io.ReadAll(request.Body)
+	assert.EqualError(t, err, "encoder failed")
+}
+
+func TestRequestBuilder_Build_StreamEncoderClosedBeforeRead(t *testing.T) {
+	started := false
+	request := buildWithBody(t, StreamEncoder(func(writer io.Writer) error {
+		started = true
+		return nil
+	}))
+
+	assert.NoError(t, request.Body.Close())
+	assert.False(t, started)
+}
+
+func TestRequestBuilder_Build_PlainReader(t *testing.T) {
+	request := buildWithBody(t, io.MultiReader(strings.NewReader("a"), strings.NewReader("b")))
+
+	assert.Equal(t, "ab", readBody(t, request.Body))
+	assert.Nil(t, request.GetBody)
+}
+
+func TestRequestBuilder_Build_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "upload")
+	assert.NoError(t, os.WriteFile(path, []byte("file content"), 0o600))
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	request := buildWithBody(t, file)
+
+	assert.Equal(t, int64(12), request.ContentLength)
+	assert.Equal(t, "file content", readBody(t, request.Body))
+	replay, err := request.GetBody()
+	assert.NoError(t, err)
+	assert.Equal(t, "file content", readBody(t, replay))
+}
@@ -0,0 +1,160 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httperror"
+	"github.com/ttanik/http-client/httptiming"
+)
+
+const defaultMaxBodyBytes = 10 << 20
+
+type decompressor interface {
+	Decompress(response *http.Response) *httperror.HTTPError
+}
+
+// Response is a response of Client.Do. Its body is read on first use, up to
+// the limit of WithMaxBodyBytes, and closed right after, so a Response only
+// needs Close when its body is never used.
+type Response struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Timing     httptiming.Timing
+	// Attempts counts retries and hedged requests.
+	Attempts int
+
+	raw          *http.Response
+	decompressor decompressor
+	maxBodyBytes int64
+
+	once      sync.Once
+	body      []byte
+	httpError *httperror.HTTPError
+}
+
+func newResponse(raw *http.Response, timing httptiming.Timing, options clientOptions) *Response {
+	decompressor, ok := options.decoder.(decompressor)
+	if !ok {
+		decompressor = httpdecoder.NewHTTPDecoder()
+	}
+
+	attempts := timing.Attempts
+	if attempts == 0 {
+		attempts = 1
+	}
+
+	return &Response{
+		StatusCode:   raw.StatusCode,
+		Status:       raw.Status,
+		Header:       raw.Header,
+		Timing:       timing,
+		Attempts:     attempts,
+		raw:          raw,
+		decompressor: decompressor,
+		maxBodyBytes: options.maxBodyBytes,
+	}
+}
+
+// Raw returns the underlying response. Reading its body directly, e.g. to
+// stream it, bypasses Bytes and the body limit.
+func (response *Response) Raw() *http.Response {
+	return response.raw
+}
+
+// IsSuccess reports a 2xx status.
+func (response *Response) IsSuccess() bool {
+	return response.StatusCode >= http.StatusOK && response.StatusCode < http.StatusMultipleChoices
+}
+
+// Bytes reads and closes the body. Later calls return the same bytes.
+func (response *Response) Bytes() ([]byte, *httperror.HTTPError) {
+	response.once.Do(response.read)
+	return response.body, response.httpError
+}
+
+// String returns the body, or an empty string when it cannot be read.
+func (response *Response) String() string {
+	body, _ := response.Bytes()
+	return string(body)
+}
+
+// Decode reads the body as JSON into target and closes it. An empty body
+// leaves target untouched.
+func (response *Response) Decode(target interface{}) *httperror.HTTPError {
+	body, httpError := response.Bytes()
+	if httpError != nil {
+		return httpError
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return &httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "error decoding response body",
+			Err:     err,
+			Time:    time.Now(),
+		}
+	}
+	return nil
+}
+
+// Close discards an unread body. It is safe to call more than once and after
+// the body has been read.
+func (response *Response) Close() error {
+	var err error
+	response.once.Do(func() {
+		response.httpError = &httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "response body already closed",
+			Time:    time.Now(),
+		}
+		if response.raw.Body != nil {
+			err = response.raw.Body.Close()
+		}
+	})
+	return err
+}
+
+func (response *Response) read() {
+	if response.raw.Body == nil {
+		return
+	}
+	defer func() {
+		_ = response.raw.Body.Close()
+	}()
+
+	if httpError := response.decompressor.Decompress(response.raw); httpError != nil {
+		response.httpError = httpError
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.raw.Body, response.maxBodyBytes+1))
+	if err != nil {
+		response.httpError = &httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "error reading response body",
+			Err:     err,
+			Time:    time.Now(),
+		}
+		return
+	}
+	if int64(len(body)) > response.maxBodyBytes {
+		response.httpError = &httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "response body too large",
+			Err:     fmt.Errorf("body exceeds %d bytes", response.maxBodyBytes),
+			Time:    time.Now(),
+		}
+		return
+	}
+	response.body = body
+}
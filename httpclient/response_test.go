@@ -0,0 +1,151 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httperror"
+	"github.com/ttanik/http-client/httprequester"
+)
+
+type trackedBody struct {
+	io.Reader
+	closed int32
+}
+
+func (body *trackedBody) Close() error {
+	atomic.AddInt32(&body.closed, 1)
+	return nil
+}
+
+func newTrackedResponse(t *testing.T, status int, body string, opts ...Option) (*Response, *trackedBody) {
+	tracked := &trackedBody{Reader: strings.NewReader(body)}
+	requester := RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		return &http.Response{StatusCode: status, Status: http.StatusText(status), Header: http.Header{}, Body: tracked}, nil
+	})
+	client := NewHTTPClient(append([]Option{WithRequester(requester)}, opts...)...)
+
+	request, httpError := client.NewRequestBuilder(context.Background()).WithEndpoint("http://rain.us/users").Build()
+	assert.Nil(t, httpError)
+	response, httpError := client.Do(request)
+	assert.Nil(t, httpError)
+	return response, tracked
+}
+
+func TestResponse_Decode(t *testing.T) {
+	response, body := newTrackedResponse(t, http.StatusOK, `{"name":"ada"}`)
+
+	var user struct {
+		Name string `json:"name"`
+	}
+	httpError := response.Decode(&user)
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, "ada", user.Name)
+	assert.True(t, response.IsSuccess())
+	assert.Equal(t, 1, response.Attempts)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&body.closed))
+	assert.Equal(t, `{"name":"ada"}`, response.String())
+	assert.NoError(t, response.Close())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&body.closed))
+}
+
+func TestResponse_DecodeEmptyBody(t *testing.T) {
+	response, body := newTrackedResponse(t, http.StatusNoContent, ``)
+
+	target := map[string]string{"name": "ada"}
+	httpError := response.Decode(&target)
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, map[string]string{"name": "ada"}, target)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&body.closed))
+}
+
+func TestResponse_DecodeError(t *testing.T) {
+	response, body := newTrackedResponse(t, http.StatusBadRequest, `not json`)
+
+	var target map[string]string
+	httpError := response.Decode(&target)
+
+	assert.Equal(t, "error decoding response body", httpError.Message)
+	assert.False(t, response.IsSuccess())
+	assert.Equal(t, "not json", response.String())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&body.closed))
+}
+
+func TestResponse_MaxBodyBytes(t *testing.T) {
+	response, body := newTrackedResponse(t, http.StatusOK, "0123456789", WithMaxBodyBytes(4))
+
+	data, httpError := response.Bytes()
+
+	assert.Nil(t, data)
+	assert.Equal(t, "response body too large", httpError.Message)
+	assert.Empty(t, response.String())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&body.closed))
+}
+
+func TestResponse_Close(t *testing.T) {
+	response, body := newTrackedResponse(t, http.StatusOK, "unread")
+
+	assert.NoError(t, response.Close())
+	assert.NoError(t, response.Close())
+	_, httpError := response.Bytes()
+
+	assert.Equal(t, "response body already closed", httpError.Message)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&body.closed))
+}
+
+func TestClient_Do(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var compressed bytes.Buffer
+		gzipWriter := gzip.NewWriter(&compressed)
+		_, _ = gzipWriter.Write([]byte(`[1,2,3]`))
+		_ = gzipWriter.Close()
+		writer.Header().Set("Content-Encoding", "gzip")
+		writer.Header().Set("X-Served-By", "test")
+		_, _ = writer.Write(compressed.Bytes())
+	}))
+	defer server.Close()
+
+	requester := httprequester.NewHTTPRequester(http.DefaultClient, nil).
+		WithRetry(httprequester.RetryPolicy{MaxAttempts: 2, Backoff: 1})
+	client := NewHTTPClient(WithRequester(requester), WithBaseURL(server.URL), WithAcceptEncoding("gzip"))
+	request, _ := client.NewRequestBuilder(context.Background()).Build()
+
+	response, httpError := client.Do(request)
+	assert.Nil(t, httpError)
+
+	var numbers []int
+	assert.Nil(t, response.Decode(&numbers))
+	assert.Equal(t, []int{1, 2, 3}, numbers)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "test", response.Header.Get("X-Served-By"))
+	assert.Equal(t, 2, response.Attempts)
+	assert.Equal(t, 2, response.Timing.Attempts)
+	assert.Equal(t, http.StatusOK, response.Raw().StatusCode)
+}
+
+func TestClient_Do_Error(t *testing.T) {
+	client := NewHTTPClient(WithRequester(RequesterFunc(func(request *http.Request) (*http.Response, *httperror.HTTPError) {
+		return nil, &httperror.HTTPError{Status: http.StatusInternalServerError, Message: "error executing request"}
+	})))
+	request, _ := client.NewRequestBuilder(context.Background()).WithEndpoint("http://rain.us").Build()
+
+	response, httpError := client.Do(request)
+
+	assert.Nil(t, response)
+	assert.Equal(t, "error executing request", httpError.Message)
+}
@@ -0,0 +1,399 @@
+package httpclienttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Requester ...
+type Requester interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Contract is what a consumer expects from a provider.
+type Contract struct {
+	Consumer     string                `json:"consumer"`
+	Provider     string                `json:"provider"`
+	Interactions []ContractInteraction `json:"interactions"`
+}
+
+// ContractInteraction ...
+type ContractInteraction struct {
+	Request  ContractRequest  `json:"request"`
+	Response ContractResponse `json:"response"`
+}
+
+// ContractRequest ...
+type ContractRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	// BodyEncoding is "text" when Body is a JSON string holding a body that
+	// was not JSON.
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+// ContractResponse describes the response shape: the status, the captured
+// headers, and a body whose JSON types, not values, the provider must match.
+type ContractResponse struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// String ...
+func (interaction ContractInteraction) String() string {
+	uri := interaction.Request.Path
+	if interaction.Request.Query != "" {
+		uri += "?" + interaction.Request.Query
+	}
+	return interaction.Request.Method + " " + uri
+}
+
+// ContractConfigs ...
+type ContractConfigs struct {
+	Consumer string
+	Provider string
+	// Path is where the contract is written when the test ends. Leave empty
+	// to only use Contract.
+	Path string
+	// Headers are captured from requests and responses. Defaults to
+	// Content-Type and Accept.
+	Headers []string
+}
+
+// NewContractRecorder wraps the requester a consumer test uses, typically a
+// Stub, and captures every interaction that goes through it.
+func NewContractRecorder(t T, requester Requester, configs ContractConfigs) *ContractRecorder {
+	if configs.Headers == nil {
+		configs.Headers = []string{"Content-Type", "Accept"}
+	}
+
+	recorder := &ContractRecorder{
+		requester: requester,
+		headers:   configs.Headers,
+		contract:  Contract{Consumer: configs.Consumer, Provider: configs.Provider},
+	}
+	if configs.Path != "" {
+		t.Cleanup(func() {
+			t.Helper()
+			if err := SaveContract(configs.Path, recorder.Contract()); err != nil {
+				t.Errorf("httpclienttest: saving contract: %v", err)
+			}
+		})
+	}
+	return recorder
+}
+
+// ContractRecorder ...
+type ContractRecorder struct {
+	requester Requester
+	headers   []string
+
+	mutex    sync.Mutex
+	contract Contract
+}
+
+// Do ...
+func (recorder *ContractRecorder) Do(request *http.Request) (*http.Response, error) {
+	request, err := bufferBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := recorder.requester.Do(request)
+	if err != nil {
+		return response, err
+	}
+
+	responseBody, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	requestBody := []byte(Body(request))
+	interaction := ContractInteraction{
+		Request: ContractRequest{
+			Method:       request.Method,
+			Path:         request.URL.Path,
+			Query:        request.URL.RawQuery,
+			Headers:      pickHeaders(request.Header, recorder.headers),
+			Body:         rawJSON(requestBody),
+			BodyEncoding: bodyEncoding(requestBody),
+		},
+		Response: ContractResponse{
+			Status:  response.StatusCode,
+			Headers: pickHeaders(response.Header, recorder.headers),
+			Body:    rawJSON(responseBody),
+		},
+	}
+	if interaction.Request.Method == "" {
+		interaction.Request.Method = http.MethodGet
+	}
+
+	recorder.mutex.Lock()
+	recorder.contract.Interactions = append(recorder.contract.Interactions, interaction)
+	recorder.mutex.Unlock()
+
+	return response, nil
+}
+
+// Contract returns the interactions captured so far.
+func (recorder *ContractRecorder) Contract() Contract {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	contract := recorder.contract
+	contract.Interactions = append([]ContractInteraction(nil), recorder.contract.Interactions...)
+	return contract
+}
+
+func pickHeaders(headers http.Header, names []string) map[string]string {
+	picked := map[string]string{}
+	for _, name := range names {
+		if value := headers.Get(name); value != "" {
+			picked[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	if len(picked) == 0 {
+		return nil
+	}
+	return picked
+}
+
+const textBodyEncoding = "text"
+
+// bodyEncoding tells which bodies rawJSON stores as JSON strings.
+func bodyEncoding(body []byte) string {
+	if len(body) == 0 || json.Valid(body) {
+		return ""
+	}
+	return textBodyEncoding
+}
+
+// rawJSON keeps JSON bodies as they are and stores anything else as a JSON
+// string.
+func rawJSON(body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	if json.Valid(body) {
+		return body
+	}
+	encoded, _ := json.Marshal(string(body))
+	return encoded
+}
+
+// SaveContract ...
+func SaveContract(path string, contract Contract) error {
+	if contract.Interactions == nil {
+		contract.Interactions = []ContractInteraction{}
+	}
+
+	content, err := json.MarshalIndent(contract, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o644)
+}
+
+// LoadContract ...
+func LoadContract(path string) (Contract, error) {
+	var contract Contract
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return contract, err
+	}
+	err = json.Unmarshal(content, &contract)
+	return contract, err
+}
+
+// Mismatch is a difference between the contract and the provider.
+type Mismatch struct {
+	Interaction string
+	Field       string
+	Expected    string
+	Actual      string
+}
+
+// String ...
+func (mismatch Mismatch) String() string {
+	return fmt.Sprintf("%s: %s expected %s, got %s", mismatch.Interaction, mismatch.Field, mismatch.Expected, mismatch.Actual)
+}
+
+// VerifyContract replays every interaction against handler in process and
+// returns how the provider's responses differ from the contract.
+func VerifyContract(contract Contract, handler http.Handler) []Mismatch {
+	var mismatches []Mismatch
+	for _, interaction := range contract.Interactions {
+		target := interaction.Request.Path
+		if interaction.Request.Query != "" {
+			target += "?" + interaction.Request.Query
+		}
+
+		var body io.Reader
+		if len(interaction.Request.Body) > 0 {
+			body = bytes.NewReader(requestBody(interaction.Request))
+		}
+		request := httptest.NewRequest(interaction.Request.Method, target, body)
+		for name, value := range interaction.Request.Headers {
+			request.Header.Set(name, value)
+		}
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		mismatches = append(mismatches, compareResponse(interaction, recorder.Result())...)
+	}
+	return mismatches
+}
+
+// AssertContract loads the contract at path, verifies it against handler
+// and reports every mismatch on t.
+func AssertContract(t T, path string, handler http.Handler) {
+	t.Helper()
+
+	contract, err := LoadContract(path)
+	if err != nil {
+		t.Errorf("httpclienttest: loading contract: %v", err)
+		return
+	}
+	for _, mismatch := range VerifyContract(contract, handler) {
+		t.Errorf("httpclienttest: contract %s -> %s: %s", contract.Consumer, contract.Provider, mismatch)
+	}
+}
+
+// requestBody undoes rawJSON for bodies that were not JSON.
+func requestBody(request ContractRequest) []byte {
+	var text string
+	if request.BodyEncoding == textBodyEncoding && json.Unmarshal(request.Body, &text) == nil {
+		return []byte(text)
+	}
+	return request.Body
+}
+
+func compareResponse(interaction ContractInteraction, response *http.Response) []Mismatch {
+	name := interaction.String()
+	var mismatches []Mismatch
+
+	expected := interaction.Response
+	if response.StatusCode != expected.Status {
+		mismatches = append(mismatches, Mismatch{
+			Interaction: name,
+			Field:       "status",
+			Expected:    fmt.Sprint(expected.Status),
+			Actual:      fmt.Sprint(response.StatusCode),
+		})
+	}
+
+	headers := make([]string, 0, len(expected.Headers))
+	for header := range expected.Headers {
+		headers = append(headers, header)
+	}
+	sort.Strings(headers)
+	for _, header := range headers {
+		if actual := response.Header.Get(header); !sameMediaType(actual, expected.Headers[header]) {
+			mismatches = append(mismatches, Mismatch{
+				Interaction: name,
+				Field:       "header " + header,
+				Expected:    expected.Headers[header],
+				Actual:      actual,
+			})
+		}
+	}
+
+	if len(expected.Body) == 0 {
+		return mismatches
+	}
+
+	body, _ := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	var want, got interface{}
+	_ = json.Unmarshal(expected.Body, &want)
+	if err := json.Unmarshal(rawJSON(body), &got); err != nil {
+		got = nil
+	}
+	for _, difference := range compareShape("body", want, got) {
+		difference.Interaction = name
+		mismatches = append(mismatches, difference)
+	}
+	return mismatches
+}
+
+// sameMediaType ignores parameters such as charset.
+func sameMediaType(actual string, expected string) bool {
+	trim := func(value string) string {
+		return strings.TrimSpace(strings.SplitN(value, ";", 2)[0])
+	}
+	return strings.EqualFold(trim(actual), trim(expected))
+}
+
+// compareShape checks that got has every field of want with the same JSON
+// type. Extra fields are fine; array elements are compared with the first
+// expected element.
+func compareShape(field string, want interface{}, got interface{}) []Mismatch {
+	if jsonType(want) != jsonType(got) {
+		return []Mismatch{{Field: field, Expected: jsonType(want), Actual: jsonType(got)}}
+	}
+
+	var mismatches []Mismatch
+	switch want := want.(type) {
+	case map[string]interface{}:
+		object := got.(map[string]interface{})
+		keys := make([]string, 0, len(want))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := object[key]
+			if !ok {
+				mismatches = append(mismatches, Mismatch{Field: field + "." + key, Expected: jsonType(want[key]), Actual: "missing"})
+				continue
+			}
+			mismatches = append(mismatches, compareShape(field+"."+key, want[key], value)...)
+		}
+	case []interface{}:
+		array := got.([]interface{})
+		if len(want) == 0 || len(array) == 0 {
+			return nil
+		}
+		for index, element := range array {
+			mismatches = append(mismatches, compareShape(fmt.Sprintf("%s[%d]", field, index), want[0], element)...)
+		}
+	}
+	return mismatches
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
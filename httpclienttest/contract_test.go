@@ -0,0 +1,112 @@
+package httpclienttest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordContract(t *testing.T, path string) {
+	fake := &fakeT{}
+	stub := NewStub(fake)
+	stub.On(http.MethodGet, "/users/{id}").Reply(http.StatusOK, map[string]interface{}{
+		"id":    1,
+		"name":  "rain",
+		"roles": []string{"admin"},
+	})
+	stub.On(http.MethodPost, "/users").Reply(http.StatusCreated, "created").WithReplyHeader("Content-Type", "text/plain")
+
+	recorder := NewContractRecorder(fake, stub, ContractConfigs{Consumer: "web", Provider: "users", Path: path})
+	client := newClient(recorder)
+	response, httpError := client.Get(context.Background(), "http://users.rain.us/users/1?expand=roles")
+	assert.Nil(t, httpError)
+	assert.JSONEq(t, `{"id":1,"name":"rain","roles":["admin"]}`, readBody(t, response))
+	response, httpError = client.Post(context.Background(), "http://users.rain.us/users", map[string]string{"name": "snow"})
+	assert.Nil(t, httpError)
+	assert.Equal(t, "created", readBody(t, response))
+
+	assert.Len(t, recorder.Contract().Interactions, 2)
+	fake.finish()
+	assert.Empty(t, fake.errors)
+}
+
+func providerHandler(user interface{}) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch {
+		case request.Method == http.MethodGet && request.URL.Path == "/users/1":
+			writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_ = json.NewEncoder(writer).Encode(user)
+		case request.Method == http.MethodPost && request.URL.Path == "/users":
+			writer.Header().Set("Content-Type", "text/plain")
+			writer.WriteHeader(http.StatusCreated)
+			_, _ = writer.Write([]byte("created as 2"))
+		default:
+			http.NotFound(writer, request)
+		}
+	})
+}
+
+func TestContract_RecordAndVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "web-users.json")
+	recordContract(t, path)
+
+	contract, err := LoadContract(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "web", contract.Consumer)
+	assert.Equal(t, "GET /users/1?expand=roles", contract.Interactions[0].String())
+	assert.JSONEq(t, `{"name":"snow"}`, string(contract.Interactions[1].Request.Body))
+	assert.Empty(t, contract.Interactions[1].Request.BodyEncoding)
+
+	provider := providerHandler(map[string]interface{}{"id": 7, "name": "other", "roles": []string{}, "extra": true})
+	assert.Empty(t, VerifyContract(contract, provider))
+}
+
+func TestContract_VerifyReportsMismatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "web-users.json")
+	recordContract(t, path)
+
+	fake := &fakeT{}
+	AssertContract(fake, path, providerHandler(map[string]interface{}{"id": "7", "roles": []int{1}}))
+
+	assert.Equal(t, []string{
+		"httpclienttest: contract web -> users: GET /users/1?expand=roles: body.id expected number, got string",
+		"httpclienttest: contract web -> users: GET /users/1?expand=roles: body.name expected string, got missing",
+		"httpclienttest: contract web -> users: GET /users/1?expand=roles: body.roles[0] expected string, got number",
+	}, fake.errors)
+}
+
+func TestContract_VerifyStatusAndHeaders(t *testing.T) {
+	contract := Contract{Interactions: []ContractInteraction{{
+		Request:  ContractRequest{Method: http.MethodDelete, Path: "/users/1"},
+		Response: ContractResponse{Status: http.StatusNoContent, Headers: map[string]string{"Content-Type": "application/json"}},
+	}}}
+
+	mismatches := VerifyContract(contract, providerHandler(nil))
+
+	assert.Equal(t, []string{
+		"DELETE /users/1: status expected 204, got 404",
+		"DELETE /users/1: header Content-Type expected application/json, got text/plain; charset=utf-8",
+	}, []string{mismatches[0].String(), mismatches[1].String()})
+}
+
+func TestContract_VerifyReplaysRequestBodies(t *testing.T) {
+	var received []string
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		received = append(received, string(body))
+	})
+	contract := Contract{Interactions: []ContractInteraction{
+		{Request: ContractRequest{Method: http.MethodPost, Path: "/notes", Body: json.RawMessage(`"hello"`)}, Response: ContractResponse{Status: http.StatusOK}},
+		{Request: ContractRequest{Method: http.MethodPost, Path: "/notes", Body: json.RawMessage(`"hello"`), BodyEncoding: "text"}, Response: ContractResponse{Status: http.StatusOK}},
+	}}
+
+	assert.Empty(t, VerifyContract(contract, handler))
+	assert.Equal(t, []string{`"hello"`, "hello"}, received)
+	assert.Equal(t, "text", bodyEncoding([]byte("hello")))
+	assert.Empty(t, bodyEncoding([]byte(`"hello"`)))
+}
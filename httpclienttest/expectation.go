@@ -0,0 +1,230 @@
+package httpclienttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Responder builds a reply from the request and its path parameters.
+type Responder func(request *http.Request, params map[string]string) (status int, body interface{})
+
+type reply struct {
+	status    int
+	body      interface{}
+	headers   http.Header
+	responder Responder
+	delay     time.Duration
+	reset     bool
+}
+
+// render returns the status and encoded body of the reply. Strings and byte
+// slices are sent as they are, anything else as JSON.
+func (reply *reply) render(request *http.Request, params map[string]string) (int, http.Header, []byte) {
+	status, body := reply.status, reply.body
+	if reply.responder != nil {
+		status, body = reply.responder(request, params)
+	}
+
+	headers := reply.headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	switch content := body.(type) {
+	case nil:
+		return status, headers, nil
+	case string:
+		return status, headers, []byte(content)
+	case []byte:
+		return status, headers, content
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return http.StatusInternalServerError, headers, []byte(err.Error())
+	}
+	if headers.Get("Content-Type") == "" {
+		headers.Set("Content-Type", "application/json")
+	}
+	return status, headers, encoded
+}
+
+// Expectation is a stubbed route. Replies are served in the order they were
+// added; the last one keeps being served once the others are used up.
+type Expectation struct {
+	method  string
+	pattern pathPattern
+	headers http.Header
+	query   map[string]string
+
+	mutex   sync.Mutex
+	replies []*reply
+	// times is the expected number of calls; negative means at least one.
+	times    int
+	requests []*http.Request
+}
+
+// WithHeader only matches requests carrying the header value.
+func (expectation *Expectation) WithHeader(name string, value string) *Expectation {
+	expectation.headers.Add(name, value)
+	return expectation
+}
+
+// WithQuery only matches requests carrying the query parameter value.
+func (expectation *Expectation) WithQuery(name string, value string) *Expectation {
+	expectation.query[name] = value
+	return expectation
+}
+
+// Reply queues a response.
+func (expectation *Expectation) Reply(status int, body interface{}) *Expectation {
+	return expectation.addReply(&reply{status: status, body: body})
+}
+
+// ReplyFunc queues a response computed from the request.
+func (expectation *Expectation) ReplyFunc(responder Responder) *Expectation {
+	return expectation.addReply(&reply{responder: responder})
+}
+
+// ResetConnection queues a reply that drops the connection without
+// answering.
+func (expectation *Expectation) ResetConnection() *Expectation {
+	return expectation.addReply(&reply{reset: true})
+}
+
+// WithReplyHeader sets a header on the last queued reply.
+func (expectation *Expectation) WithReplyHeader(name string, value string) *Expectation {
+	last := expectation.lastReply()
+	if last.headers == nil {
+		last.headers = http.Header{}
+	}
+	last.headers.Add(name, value)
+	return expectation
+}
+
+// Delay holds the last queued reply back for the given duration, or until
+// the request is cancelled.
+func (expectation *Expectation) Delay(delay time.Duration) *Expectation {
+	expectation.lastReply().delay = delay
+	return expectation
+}
+
+// Times expects exactly n calls. Without it, at least one call is expected.
+func (expectation *Expectation) Times(n int) *Expectation {
+	expectation.mutex.Lock()
+	defer expectation.mutex.Unlock()
+
+	expectation.times = n
+	return expectation
+}
+
+// Once ...
+func (expectation *Expectation) Once() *Expectation {
+	return expectation.Times(1)
+}
+
+// Calls returns how many requests the expectation answered.
+func (expectation *Expectation) Calls() int {
+	expectation.mutex.Lock()
+	defer expectation.mutex.Unlock()
+
+	return len(expectation.requests)
+}
+
+// Requests returns the requests the expectation answered.
+func (expectation *Expectation) Requests() []*http.Request {
+	expectation.mutex.Lock()
+	defer expectation.mutex.Unlock()
+
+	return append([]*http.Request(nil), expectation.requests...)
+}
+
+func (expectation *Expectation) addReply(next *reply) *Expectation {
+	expectation.mutex.Lock()
+	defer expectation.mutex.Unlock()
+
+	expectation.replies = append(expectation.replies, next)
+	return expectation
+}
+
+func (expectation *Expectation) lastReply() *reply {
+	expectation.mutex.Lock()
+	defer expectation.mutex.Unlock()
+
+	if len(expectation.replies) == 0 {
+		expectation.replies = append(expectation.replies, &reply{status: http.StatusOK})
+	}
+	return expectation.replies[len(expectation.replies)-1]
+}
+
+func (expectation *Expectation) match(request *http.Request) (map[string]string, bool) {
+	if expectation.method != request.Method {
+		return nil, false
+	}
+	params, ok := expectation.pattern.match(request.URL.Path)
+	if !ok {
+		return nil, false
+	}
+
+	for name, values := range expectation.headers {
+		for _, value := range values {
+			if !contains(request.Header.Values(name), value) {
+				return nil, false
+			}
+		}
+	}
+	query := request.URL.Query()
+	for name, value := range expectation.query {
+		if !contains(query[name], value) {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// serve records the call and returns the reply for it.
+func (expectation *Expectation) serve(request *http.Request) *reply {
+	expectation.mutex.Lock()
+	defer expectation.mutex.Unlock()
+
+	call := len(expectation.requests)
+	expectation.requests = append(expectation.requests, request)
+	if len(expectation.replies) == 0 {
+		return &reply{status: http.StatusOK}
+	}
+	if call >= len(expectation.replies) {
+		call = len(expectation.replies) - 1
+	}
+	return expectation.replies[call]
+}
+
+func (expectation *Expectation) unmet() string {
+	expectation.mutex.Lock()
+	defer expectation.mutex.Unlock()
+
+	calls := len(expectation.requests)
+	switch {
+	case expectation.times >= 0 && calls != expectation.times:
+		return fmt.Sprintf("%s expected %d calls, got %d", expectation, expectation.times, calls)
+	case expectation.times < 0 && calls == 0:
+		return fmt.Sprintf("%s was never called", expectation)
+	}
+	return ""
+}
+
+// String ...
+func (expectation *Expectation) String() string {
+	return expectation.method + " " + expectation.pattern.raw
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
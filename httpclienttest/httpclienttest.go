@@ -0,0 +1,272 @@
+package httpclienttest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrConnectionReset is returned by Do for replies queued with
+// ResetConnection.
+var ErrConnectionReset = errors.New("connection reset by stub")
+
+// T is the part of testing.T the stub needs.
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Cleanup(cleanup func())
+}
+
+// Stub answers requests from a list of expectations, either in memory via
+// Do, which makes it a Requester for httprequester.NewHTTPRequester, or over
+// the network via Server. Expectations are verified when the test ends.
+type Stub struct {
+	t T
+
+	mutex        sync.Mutex
+	expectations []*Expectation
+	unmatched    []string
+	server       *httptest.Server
+}
+
+// NewStub ...
+func NewStub(t T) *Stub {
+	stub := &Stub{t: t}
+	t.Cleanup(func() {
+		stub.mutex.Lock()
+		server := stub.server
+		stub.mutex.Unlock()
+		if server != nil {
+			server.Close()
+		}
+		stub.Verify()
+	})
+	return stub
+}
+
+// On adds an expectation for method and a path pattern such as
+// /users/{id}. Expectations added first win when several match.
+func (stub *Stub) On(method string, pattern string) *Expectation {
+	expectation := &Expectation{
+		method:  method,
+		pattern: newPathPattern(pattern),
+		headers: http.Header{},
+		query:   map[string]string{},
+		times:   -1,
+	}
+
+	stub.mutex.Lock()
+	defer stub.mutex.Unlock()
+
+	stub.expectations = append(stub.expectations, expectation)
+	return expectation
+}
+
+// Reset drops every expectation and recorded call.
+func (stub *Stub) Reset() {
+	stub.mutex.Lock()
+	defer stub.mutex.Unlock()
+
+	stub.expectations = nil
+	stub.unmatched = nil
+}
+
+// Server starts an httptest.Server backed by the stub on first use.
+func (stub *Stub) Server() *httptest.Server {
+	stub.mutex.Lock()
+	defer stub.mutex.Unlock()
+
+	if stub.server == nil {
+		stub.server = httptest.NewServer(stub)
+	}
+	return stub.server
+}
+
+// URL returns the base URL of Server.
+func (stub *Stub) URL() string {
+	return stub.Server().URL
+}
+
+// Verify reports requests no expectation matched and expectations whose
+// call count was not met.
+func (stub *Stub) Verify() {
+	stub.t.Helper()
+
+	stub.mutex.Lock()
+	expectations := append([]*Expectation(nil), stub.expectations...)
+	unmatched := append([]string(nil), stub.unmatched...)
+	stub.mutex.Unlock()
+
+	for _, request := range unmatched {
+		stub.t.Errorf("httpclienttest: unexpected request %s", request)
+	}
+	for _, expectation := range expectations {
+		if problem := expectation.unmet(); problem != "" {
+			stub.t.Errorf("httpclienttest: %s", problem)
+		}
+	}
+}
+
+func (stub *Stub) find(request *http.Request) (*reply, map[string]string) {
+	stub.mutex.Lock()
+	expectations := append([]*Expectation(nil), stub.expectations...)
+	stub.mutex.Unlock()
+
+	for _, expectation := range expectations {
+		if params, ok := expectation.match(request); ok {
+			return expectation.serve(request), params
+		}
+	}
+
+	stub.mutex.Lock()
+	stub.unmatched = append(stub.unmatched, request.Method+" "+request.URL.RequestURI())
+	stub.mutex.Unlock()
+	return nil, nil
+}
+
+// wait sleeps for the reply delay and reports whether the request is still
+// wanted afterwards.
+func wait(request *http.Request, delay time.Duration) bool {
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-request.Context().Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Do ...
+func (stub *Stub) Do(request *http.Request) (*http.Response, error) {
+	request, err := bufferBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := httptest.NewRecorder()
+	next, params := stub.find(request)
+	if next == nil {
+		writeUnmatched(recorder, request)
+		return stub.result(recorder, request), nil
+	}
+
+	if !wait(request, next.delay) {
+		return nil, request.Context().Err()
+	}
+	if next.reset {
+		return nil, fmt.Errorf("%s %s: %w", request.Method, request.URL, ErrConnectionReset)
+	}
+
+	write(recorder, next, request, params)
+	return stub.result(recorder, request), nil
+}
+
+func (stub *Stub) result(recorder *httptest.ResponseRecorder, request *http.Request) *http.Response {
+	response := recorder.Result()
+	response.Request = request
+	return response
+}
+
+// ServeHTTP ...
+func (stub *Stub) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	request, err := bufferBody(request)
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	next, params := stub.find(request)
+	if next == nil {
+		writeUnmatched(writer, request)
+		return
+	}
+
+	if !wait(request, next.delay) {
+		return
+	}
+	if next.reset {
+		resetConnection(writer)
+		return
+	}
+
+	write(writer, next, request, params)
+}
+
+func write(writer http.ResponseWriter, next *reply, request *http.Request, params map[string]string) {
+	status, headers, body := next.render(request, params)
+	for name, values := range headers {
+		writer.Header()[name] = values
+	}
+	writer.WriteHeader(status)
+	_, _ = writer.Write(body)
+}
+
+func writeUnmatched(writer http.ResponseWriter, request *http.Request) {
+	http.Error(writer, "httpclienttest: no stub for "+request.Method+" "+request.URL.Path, http.StatusNotImplemented)
+}
+
+// resetConnection closes the TCP connection with SO_LINGER 0, so the client
+// sees a reset rather than a clean EOF.
+func resetConnection(writer http.ResponseWriter) {
+	hijacker, ok := writer.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+
+	connection, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	if tcpConnection, ok := connection.(*net.TCPConn); ok {
+		_ = tcpConnection.SetLinger(0)
+	}
+	_ = connection.Close()
+}
+
+// bufferBody reads the body so that requests kept for assertions can still
+// be inspected after the caller is done with them.
+func bufferBody(request *http.Request) (*http.Request, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return request, nil
+	}
+
+	body, err := io.ReadAll(request.Body)
+	_ = request.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := request.Clone(request.Context())
+	buffered.Body = io.NopCloser(bytes.NewReader(body))
+	buffered.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return buffered, nil
+}
+
+// Body returns the body of a request recorded by the stub.
+func Body(request *http.Request) string {
+	if request.GetBody == nil {
+		return ""
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return ""
+	}
+	var builder strings.Builder
+	_, _ = io.Copy(&builder, body)
+	return builder.String()
+}
@@ -0,0 +1,171 @@
+package httpclienttest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httpmarshal"
+	"github.com/ttanik/http-client/httprequester"
+)
+
+type fakeT struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Cleanup(cleanup func()) {
+	t.cleanups = append(t.cleanups, cleanup)
+}
+
+func (t *fakeT) finish() {
+	for _, cleanup := range t.cleanups {
+		cleanup()
+	}
+}
+
+func newClient(requester httprequester.Requester) *httpclient.Client {
+	return httpclient.NewHTTPClient(
+		httpclient.WithRequester(httprequester.NewHTTPRequester(requester, httpdecoder.NewHTTPDecoder())),
+		httpclient.WithMarshaller(httpmarshal.NewHTTPMarshal()),
+	)
+}
+
+func readBody(t *testing.T, response *http.Response) string {
+	body, err := io.ReadAll(response.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, response.Body.Close())
+	return string(body)
+}
+
+func TestStub_InMemory(t *testing.T) {
+	stub := NewStub(t)
+	users := stub.On(http.MethodGet, "/users/{id}").
+		WithHeader("X-Tenant", "rain").
+		ReplyFunc(func(_ *http.Request, params map[string]string) (int, interface{}) {
+			return http.StatusOK, map[string]string{"id": params["id"]}
+		}).
+		Times(2)
+
+	client := newClient(stub)
+	for _, id := range []string{"1", "2"} {
+		request, httpError := client.NewRequestBuilder(context.Background()).
+			WithEndpoint("http://rain.us/users/" + id).
+			WithHeaders(map[string]string{"X-Tenant": "rain"}).
+			Build()
+		assert.Nil(t, httpError)
+		response, httpError := client.ExecuteRequest(request)
+		assert.Nil(t, httpError)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+		assert.JSONEq(t, `{"id":"`+id+`"}`, readBody(t, response))
+	}
+	assert.Equal(t, 2, users.Calls())
+}
+
+func TestStub_Server(t *testing.T) {
+	stub := NewStub(t)
+	created := stub.On(http.MethodPost, "/users").
+		WithQuery("notify", "true").
+		Reply(http.StatusCreated, "created").
+		WithReplyHeader("Location", "/users/1").
+		Once()
+
+	response, httpError := newClient(http.DefaultClient).
+		Post(context.Background(), stub.URL()+"/users?notify=true", map[string]string{"name": "rain"})
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.Equal(t, "/users/1", response.Header.Get("Location"))
+	assert.Equal(t, "created", readBody(t, response))
+	assert.JSONEq(t, `{"name":"rain"}`, Body(created.Requests()[0]))
+}
+
+func TestStub_SequencedReplies(t *testing.T) {
+	stub := NewStub(t)
+	stub.On(http.MethodGet, "/status").
+		Reply(http.StatusServiceUnavailable, "busy").
+		Reply(http.StatusOK, "ok")
+
+	var statuses []int
+	for i := 0; i < 3; i++ {
+		request, _ := http.NewRequest(http.MethodGet, "http://rain.us/status", nil)
+		response, err := stub.Do(request)
+		assert.NoError(t, err)
+		statuses = append(statuses, response.StatusCode)
+	}
+
+	assert.Equal(t, []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusOK}, statuses)
+}
+
+func TestStub_Delay(t *testing.T) {
+	stub := NewStub(t)
+	stub.On(http.MethodGet, "/slow").Reply(http.StatusOK, nil).Delay(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, httpError := newClient(http.DefaultClient).Get(ctx, stub.URL()+"/slow")
+
+	assert.Equal(t, http.StatusGatewayTimeout, httpError.Status)
+}
+
+func TestStub_ResetConnection(t *testing.T) {
+	stub := NewStub(t)
+	stub.On(http.MethodGet, "/flaky").ResetConnection().Times(2)
+
+	_, httpError := newClient(http.DefaultClient).Get(context.Background(), stub.URL()+"/flaky")
+	assert.Equal(t, http.StatusInternalServerError, httpError.Status)
+
+	request, _ := http.NewRequest(http.MethodGet, "http://rain.us/flaky", nil)
+	_, err := stub.Do(request)
+	assert.ErrorIs(t, err, ErrConnectionReset)
+}
+
+func TestStub_VerifiesOnCleanup(t *testing.T) {
+	fake := &fakeT{}
+	stub := NewStub(fake)
+	stub.On(http.MethodGet, "/never")
+	stub.On(http.MethodGet, "/twice").Times(2)
+	stub.On(http.MethodDelete, "/users/{id}").Times(0)
+
+	request, _ := http.NewRequest(http.MethodGet, "http://rain.us/twice", nil)
+	_, _ = stub.Do(request)
+	request, _ = http.NewRequest(http.MethodGet, "http://rain.us/unknown?x=1", nil)
+	response, _ := stub.Do(request)
+	fake.finish()
+
+	assert.Equal(t, http.StatusNotImplemented, response.StatusCode)
+	assert.Equal(t, []string{
+		"httpclienttest: unexpected request GET /unknown?x=1",
+		"httpclienttest: GET /never was never called",
+		"httpclienttest: GET /twice expected 2 calls, got 1",
+	}, fake.errors)
+}
+
+func TestStub_Reset(t *testing.T) {
+	fake := &fakeT{}
+	stub := NewStub(fake)
+	stub.On(http.MethodGet, "/never")
+	stub.Reset()
+	stub.On(http.MethodGet, "/once").Reply(http.StatusOK, strings.Repeat("a", 3))
+
+	request, _ := http.NewRequest(http.MethodGet, "http://rain.us/once", nil)
+	response, err := stub.Do(request)
+	fake.finish()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "aaa", readBody(t, response))
+	assert.Empty(t, fake.errors)
+}
@@ -0,0 +1,40 @@
+package httpclienttest
+
+import "strings"
+
+// pathPattern matches paths like /users/{id}/posts. A {name} segment
+// matches any single non-empty segment and is captured as a parameter.
+type pathPattern struct {
+	raw      string
+	segments []string
+}
+
+func newPathPattern(pattern string) pathPattern {
+	return pathPattern{raw: pattern, segments: splitPath(pattern)}
+}
+
+func (pattern pathPattern) match(path string) (map[string]string, bool) {
+	segments := splitPath(path)
+	if len(segments) != len(pattern.segments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for index, segment := range pattern.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if segments[index] == "" {
+				return nil, false
+			}
+			params[segment[1:len(segment)-1]] = segments[index]
+			continue
+		}
+		if segment != segments[index] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
@@ -0,0 +1,22 @@
+package httpclienttest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathPattern_Match(t *testing.T) {
+	pattern := newPathPattern("/users/{id}/posts/{post}")
+
+	params, ok := pattern.match("/users/7/posts/42")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]string{"id": "7", "post": "42"}, params)
+
+	_, ok = pattern.match("/users/7/posts")
+	assert.False(t, ok)
+	_, ok = pattern.match("/users//posts/42")
+	assert.False(t, ok)
+	_, ok = newPathPattern("/").match("/")
+	assert.True(t, ok)
+}
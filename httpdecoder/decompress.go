@@ -0,0 +1,202 @@
+package httpdecoder
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ttanik/http-client/httperror"
+)
+
+const defaultMaxDecompressedBytes = 100 << 20
+
+// ErrDecompressedTooLarge is returned when a compressed body expands beyond
+// the decoder's limit.
+var ErrDecompressedTooLarge = errors.New("decompressed body exceeds limit")
+
+// Decompressor wraps a compressed body for a Content-Encoding.
+type Decompressor func(body io.Reader) (io.ReadCloser, error)
+
+func defaultDecompressors() map[string]Decompressor {
+	return map[string]Decompressor{
+		"gzip":    gzipDecompressor,
+		"x-gzip":  gzipDecompressor,
+		"deflate": deflateDecompressor,
+		"zstd":    zstdDecompressor,
+	}
+}
+
+// WithDecompressor registers a decompressor, e.g. for "br".
+func (d *Decoder) WithDecompressor(encoding string, decompressor Decompressor) *Decoder {
+	if d.decompressors == nil {
+		d.decompressors = defaultDecompressors()
+	}
+	d.decompressors[strings.ToLower(encoding)] = decompressor
+	return d
+}
+
+// WithMaxDecompressedBytes caps the size of a decompressed body.
+func (d *Decoder) WithMaxDecompressedBytes(maxBytes int64) *Decoder {
+	d.maxDecompressedBytes = maxBytes
+	return d
+}
+
+// AcceptEncoding lists the registered encodings for an Accept-Encoding
+// header.
+func (d *Decoder) AcceptEncoding() string {
+	encodings := []string{"gzip", "deflate", "zstd"}
+	var extra []string
+	for encoding := range d.decompressors {
+		if encoding != "x-gzip" && !contains(encodings, encoding) {
+			extra = append(extra, encoding)
+		}
+	}
+	sort.Strings(extra)
+	return strings.Join(append(encodings, extra...), ", ")
+}
+
+// Decompress replaces a compressed response body with its decompressed,
+// size-limited content according to Content-Encoding.
+func (d *Decoder) Decompress(response *http.Response) *httperror.HTTPError {
+	encodings := contentEncodings(response.Header)
+	if len(encodings) == 0 || response.Body == nil {
+		return nil
+	}
+
+	decompressors := d.decompressors
+	if decompressors == nil {
+		decompressors = defaultDecompressors()
+	}
+
+	body := response.Body
+	var reader io.Reader = body
+	closers := []io.Closer{body}
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decompressor, ok := decompressors[encodings[i]]
+		if !ok {
+			return &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "unsupported content encoding " + encodings[i],
+			}
+		}
+
+		decompressed, err := decompressor(reader)
+		if err != nil {
+			return &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "error decompressing response body",
+				Err:     err,
+			}
+		}
+		reader = decompressed
+		closers = append(closers, decompressed)
+	}
+
+	maxBytes := d.maxDecompressedBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxDecompressedBytes
+	}
+
+	response.Body = &decompressedBody{
+		reader:    reader,
+		remaining: maxBytes,
+		closers:   closers,
+	}
+	response.Header.Del("Content-Encoding")
+	response.Header.Del("Content-Length")
+	response.ContentLength = -1
+	response.Uncompressed = true
+
+	return nil
+}
+
+type decompressedBody struct {
+	reader    io.Reader
+	remaining int64
+	closers   []io.Closer
+}
+
+// Read ...
+func (body *decompressedBody) Read(p []byte) (int, error) {
+	if body.remaining <= 0 {
+		// Only fail if there really is more data.
+		var probe [1]byte
+		n, err := body.reader.Read(probe[:])
+		if n > 0 {
+			return 0, ErrDecompressedTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > body.remaining {
+		p = p[:body.remaining]
+	}
+	n, err := body.reader.Read(p)
+	body.remaining -= int64(n)
+	return n, err
+}
+
+// Close ...
+func (body *decompressedBody) Close() error {
+	var closeErr error
+	for i := len(body.closers) - 1; i >= 0; i-- {
+		if err := body.closers[i].Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	return closeErr
+}
+
+func contentEncodings(header http.Header) []string {
+	var encodings []string
+	for _, value := range header.Values("Content-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if encoding != "" && encoding != "identity" {
+				encodings = append(encodings, encoding)
+			}
+		}
+	}
+	return encodings
+}
+
+func gzipDecompressor(body io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(body)
+}
+
+// deflateDecompressor accepts zlib-wrapped data as RFC 9110 requires, and
+// falls back to raw deflate which some servers send instead.
+func deflateDecompressor(body io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(body)
+
+	// A zlib header is a CMF/FLG pair whose 16-bit value is a multiple of 31.
+	header, err := buffered.Peek(2)
+	if err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+func zstdDecompressor(body io.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return decoder.IOReadCloser(), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package httpdecoder
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+type payload struct {
+	Name string `json:"name"`
+}
+
+func compressed(t *testing.T, encoding string, content string) []byte {
+	var buffer bytes.Buffer
+	var writer io.WriteCloser
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(&buffer)
+	case "deflate":
+		writer = zlib.NewWriter(&buffer)
+	case "raw-deflate":
+		writer, _ = flate.NewWriter(&buffer, flate.DefaultCompression)
+	case "zstd":
+		writer, _ = zstd.NewWriter(&buffer)
+	}
+	_, err := writer.Write([]byte(content))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	return buffer.Bytes()
+}
+
+func newCompressedResponse(encoding string, body []byte) *http.Response {
+	return &http.Response{
+		Header: http.Header{"Content-Encoding": []string{encoding}},
+		Body:   io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+func TestDecoder_DecodeResponseBody_Compressed(t *testing.T) {
+	for _, encoding := range []string{"gzip", "deflate", "raw-deflate", "zstd"} {
+		header := encoding
+		if encoding == "raw-deflate" {
+			header = "deflate"
+		}
+		response := newCompressedResponse(header, compressed(t, encoding, `{"name":"test"}`))
+
+		var target payload
+		httpError := NewHTTPDecoder().DecodeResponseBody(context.Background(), response, &target)
+
+		assert.Nil(t, httpError, encoding)
+		assert.Equal(t, "test", target.Name, encoding)
+		assert.Empty(t, response.Header.Get("Content-Encoding"))
+	}
+}
+
+func TestDecoder_DecodeResponseBody_DecompressedTooLarge(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("a", 1000) + `"}`
+	response := newCompressedResponse("gzip", compressed(t, "gzip", body))
+
+	var target payload
+	httpError := NewHTTPDecoder().WithMaxDecompressedBytes(100).DecodeResponseBody(context.Background(), response, &target)
+
+	assert.Equal(t, "decompressed response body too large", httpError.Message)
+	assert.ErrorIs(t, httpError, ErrDecompressedTooLarge)
+}
+
+func TestDecoder_DecodeResponseBody_UnsupportedEncoding(t *testing.T) {
+	response := newCompressedResponse("br", []byte("??"))
+
+	var target payload
+	httpError := NewHTTPDecoder().DecodeResponseBody(context.Background(), response, &target)
+
+	assert.Equal(t, "unsupported content encoding br", httpError.Message)
+}
+
+func TestDecoder_WithDecompressor(t *testing.T) {
+	reverse := func(body io.Reader) (io.ReadCloser, error) {
+		content, err := io.ReadAll(body)
+		for i, j := 0, len(content)-1; i < j; i, j = i+1, j-1 {
+			content[i], content[j] = content[j], content[i]
+		}
+		return io.NopCloser(bytes.NewReader(content)), err
+	}
+	decoder := NewHTTPDecoder().WithDecompressor("br", reverse)
+	response := newCompressedResponse("br", []byte(`}"tset":"eman"{`))
+
+	var target payload
+	httpError := decoder.DecodeResponseBody(context.Background(), response, &target)
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, "test", target.Name)
+	assert.Equal(t, "gzip, deflate, zstd, br", decoder.AcceptEncoding())
+}
+
+func TestDecoder_Decompress_Identity(t *testing.T) {
+	body := io.NopCloser(strings.NewReader("plain"))
+	response := &http.Response{Header: http.Header{}, Body: body}
+
+	assert.Nil(t, NewHTTPDecoder().Decompress(response))
+	assert.Equal(t, body, response.Body)
+}
@@ -3,6 +3,7 @@ package httpdecoder
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -12,11 +13,16 @@ import (
 
 // NewHTTPDecoder ...
 func NewHTTPDecoder() *Decoder {
-	return &Decoder{}
+	return &Decoder{
+		decompressors:        defaultDecompressors(),
+		maxDecompressedBytes: defaultMaxDecompressedBytes,
+	}
 }
 
 // Decoder ...
 type Decoder struct {
+	decompressors        map[string]Decompressor
+	maxDecompressedBytes int64
 }
 
 // DecodeResponseBody ...
@@ -42,14 +48,25 @@ func (d *Decoder) DecodeResponseBody(ctx context.Context, response *http.Respons
 		}
 	}
 
+	httpError := d.Decompress(response)
 	defer func() {
 		err := response.Body.Close()
 		if err != nil {
 			fmt.Printf("error closing response body %s", err)
 		}
 	}()
+	if httpError != nil {
+		return httpError
+	}
 
 	err := json.NewDecoder(response.Body).Decode(target)
+	if errors.Is(err, ErrDecompressedTooLarge) {
+		return &httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "decompressed response body too large",
+			Err:     err,
+		}
+	}
 	if err != nil {
 		return &httperror.HTTPError{
 			Status:  http.StatusInternalServerError,
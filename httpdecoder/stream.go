@@ -0,0 +1,222 @@
+package httpdecoder
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ttanik/http-client/httperror"
+)
+
+// DecodeStream decodes a top-level JSON array or newline-delimited JSON one
+// element at a time. Compressed bodies are decompressed like in
+// DecodeResponseBody, up to the default size limit. next returns false once the stream is exhausted or an
+// error occurred. The body is closed when the stream ends, on error, when ctx
+// is done or when stop is called; stop is safe to call more than once.
+func DecodeStream[T any](ctx context.Context, response *http.Response) (next func() (T, bool, *httperror.HTTPError), stop func()) {
+	if response == nil {
+		return failedStream[T](&httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "response cannot be nil",
+		})
+	}
+
+	if response.Body == nil {
+		return failedStream[T](&httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "response body cannot be nil",
+		})
+	}
+
+	if httpError := NewHTTPDecoder().Decompress(response); httpError != nil {
+		if err := response.Body.Close(); err != nil {
+			fmt.Printf("error closing response body %s", err)
+		}
+		return failedStream[T](httpError)
+	}
+
+	stream := newStream[T](ctx, response)
+	return stream.next, stream.stop
+}
+
+// DecodeEach calls handle for every element of the stream. Returning an error
+// from handle stops the iteration and closes the body.
+func DecodeEach[T any](ctx context.Context, response *http.Response, handle func(item T) error) *httperror.HTTPError {
+	next, stop := DecodeStream[T](ctx, response)
+	defer stop()
+
+	for {
+		item, ok, httpError := next()
+		if httpError != nil {
+			return httpError
+		}
+		if !ok {
+			return nil
+		}
+
+		if err := handle(item); err != nil {
+			var handleError *httperror.HTTPError
+			if errors.As(err, &handleError) {
+				return handleError
+			}
+			return &httperror.HTTPError{
+				Status:  http.StatusInternalServerError,
+				Message: "error handling stream element",
+				Err:     err,
+			}
+		}
+	}
+}
+
+type stream[T any] struct {
+	ctx         context.Context
+	body        io.ReadCloser
+	reader      *bufio.Reader
+	decoder     *json.Decoder
+	contentType string
+	ndjson      bool
+	started     bool
+	finished    bool
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newStream[T any](ctx context.Context, response *http.Response) *stream[T] {
+	reader := bufio.NewReader(response.Body)
+	s := &stream[T]{
+		ctx:         ctx,
+		body:        response.Body,
+		reader:      reader,
+		decoder:     json.NewDecoder(reader),
+		contentType: response.Header.Get("Content-Type"),
+		done:        make(chan struct{}),
+	}
+
+	// Closing the body is the only way to unblock a pending read.
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.close()
+		case <-s.done:
+		}
+	}()
+
+	return s
+}
+
+func (s *stream[T]) next() (T, bool, *httperror.HTTPError) {
+	var item T
+	if s.finished {
+		return item, false, nil
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		return s.fail(err)
+	}
+
+	if !s.started {
+		s.started = true
+		s.ndjson = isNDJSON(s.contentType) || !startsWithArray(s.reader)
+		if !s.ndjson {
+			if _, err := s.decoder.Token(); err != nil {
+				return s.fail(err)
+			}
+		}
+	}
+
+	if !s.ndjson && !s.decoder.More() {
+		if _, err := s.decoder.Token(); err != nil {
+			return s.fail(err)
+		}
+		s.stop()
+		return item, false, nil
+	}
+
+	err := s.decoder.Decode(&item)
+	if s.ndjson && err == io.EOF {
+		s.stop()
+		return item, false, nil
+	}
+	if err != nil {
+		return s.fail(err)
+	}
+
+	return item, true, nil
+}
+
+func (s *stream[T]) fail(err error) (T, bool, *httperror.HTTPError) {
+	var item T
+	s.stop()
+
+	if ctxErr := s.ctx.Err(); ctxErr != nil {
+		err = ctxErr
+	}
+	if errors.Is(err, ErrDecompressedTooLarge) {
+		return item, false, &httperror.HTTPError{
+			Status:  http.StatusInternalServerError,
+			Message: "decompressed response body too large",
+			Err:     err,
+		}
+	}
+	return item, false, &httperror.HTTPError{
+		Status:  http.StatusInternalServerError,
+		Message: "error decoding response body",
+		Err:     err,
+	}
+}
+
+// stop is called from the iterating goroutine; close may also be called when
+// ctx is done.
+func (s *stream[T]) stop() {
+	s.finished = true
+	s.close()
+}
+
+func (s *stream[T]) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if err := s.body.Close(); err != nil {
+			fmt.Printf("error closing response body %s", err)
+		}
+	})
+}
+
+func failedStream[T any](httpError *httperror.HTTPError) (func() (T, bool, *httperror.HTTPError), func()) {
+	returned := false
+	next := func() (T, bool, *httperror.HTTPError) {
+		var item T
+		if returned {
+			return item, false, nil
+		}
+		returned = true
+		return item, false, httpError
+	}
+	return next, func() {}
+}
+
+func isNDJSON(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.Contains(contentType, "ndjson") || strings.Contains(contentType, "jsonl")
+}
+
+func startsWithArray(reader *bufio.Reader) bool {
+	for {
+		r, _, err := reader.ReadRune()
+		if err != nil {
+			return false
+		}
+		if unicode.IsSpace(r) {
+			continue
+		}
+		_ = reader.UnreadRune()
+		return r == '['
+	}
+}
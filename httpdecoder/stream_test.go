@@ -0,0 +1,155 @@
+package httpdecoder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	ID int `json:"id"`
+}
+
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (body *trackingBody) Close() error {
+	body.closed = true
+	return nil
+}
+
+func newStreamResponse(contentType, content string) (*http.Response, *trackingBody) {
+	body := &trackingBody{Reader: strings.NewReader(content)}
+	return &http.Response{
+		Header: http.Header{"Content-Type": []string{contentType}},
+		Body:   body,
+	}, body
+}
+
+func collect(t *testing.T, response *http.Response) []int {
+	var ids []int
+	httpError := DecodeEach(context.Background(), response, func(element item) error {
+		ids = append(ids, element.ID)
+		return nil
+	})
+	assert.Nil(t, httpError)
+	return ids
+}
+
+func TestDecodeStream_Array(t *testing.T) {
+	response, body := newStreamResponse("application/json", ` [{"id":1},{"id":2},{"id":3}]`)
+
+	assert.Equal(t, []int{1, 2, 3}, collect(t, response))
+	assert.True(t, body.closed)
+}
+
+func TestDecodeStream_NDJSON(t *testing.T) {
+	response, body := newStreamResponse("application/x-ndjson", "{\"id\":1}\n{\"id\":2}\n")
+
+	assert.Equal(t, []int{1, 2}, collect(t, response))
+	assert.True(t, body.closed)
+}
+
+func TestDecodeStream_Gzip(t *testing.T) {
+	response := newCompressedResponse("gzip", compressed(t, "gzip", "{\"id\":1}\n{\"id\":2}\n"))
+	response.Header.Set("Content-Type", "application/x-ndjson")
+
+	assert.Equal(t, []int{1, 2}, collect(t, response))
+}
+
+func TestDecodeStream_UnsupportedEncoding(t *testing.T) {
+	response, body := newStreamResponse("application/json", `[{"id":1}]`)
+	response.Header.Set("Content-Encoding", "br")
+
+	next, stop := DecodeStream[item](context.Background(), response)
+	defer stop()
+	_, ok, httpError := next()
+
+	assert.False(t, ok)
+	assert.Equal(t, "unsupported content encoding br", httpError.Message)
+	assert.True(t, body.closed)
+}
+
+func TestDecodeStream_NDJSONWithoutContentType(t *testing.T) {
+	response, _ := newStreamResponse("", "{\"id\":1}\n{\"id\":2}")
+
+	assert.Equal(t, []int{1, 2}, collect(t, response))
+}
+
+func TestDecodeStream_StopEarly(t *testing.T) {
+	response, body := newStreamResponse("application/json", `[{"id":1},{"id":2}]`)
+
+	next, stop := DecodeStream[item](context.Background(), response)
+	element, ok, httpError := next()
+	stop()
+	stop()
+
+	assert.Nil(t, httpError)
+	assert.True(t, ok)
+	assert.Equal(t, 1, element.ID)
+	assert.True(t, body.closed)
+	_, ok, _ = next()
+	assert.False(t, ok)
+}
+
+func TestDecodeStream_InvalidElement(t *testing.T) {
+	response, body := newStreamResponse("application/json", `[{"id":1},{"id":"x"}]`)
+
+	next, _ := DecodeStream[item](context.Background(), response)
+	_, ok, httpError := next()
+	assert.True(t, ok)
+	assert.Nil(t, httpError)
+
+	_, ok, httpError = next()
+	assert.False(t, ok)
+	assert.Equal(t, "error decoding response body", httpError.Message)
+	assert.True(t, body.closed)
+}
+
+func TestDecodeStream_ContextCancelled(t *testing.T) {
+	reader, writer := io.Pipe()
+	response := &http.Response{Body: reader}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	next, stop := DecodeStream[item](ctx, response)
+	defer stop()
+	go func() {
+		_, _ = writer.Write([]byte(`[{"id":1},`))
+		cancel()
+	}()
+
+	_, ok, httpError := next()
+	assert.True(t, ok)
+	assert.Nil(t, httpError)
+
+	_, ok, httpError = next()
+	assert.False(t, ok)
+	assert.ErrorIs(t, httpError, context.Canceled)
+}
+
+func TestDecodeEach_HandlerError(t *testing.T) {
+	response, body := newStreamResponse("application/json", `[{"id":1},{"id":2}]`)
+
+	httpError := DecodeEach(context.Background(), response, func(element item) error {
+		return errors.New("stop")
+	})
+
+	assert.Equal(t, "error handling stream element", httpError.Message)
+	assert.True(t, body.closed)
+}
+
+func TestDecodeStream_NilResponse(t *testing.T) {
+	next, stop := DecodeStream[item](context.Background(), nil)
+	defer stop()
+
+	_, ok, httpError := next()
+	assert.False(t, ok)
+	assert.Equal(t, "response cannot be nil", httpError.Message)
+}
@@ -3,6 +3,8 @@ package httperror
 import (
 	"fmt"
 	"time"
+
+	"github.com/ttanik/http-client/httptiming"
 )
 
 const (
@@ -18,6 +20,8 @@ type HTTPError struct {
 	Message string    `json:"message"`
 	Err     error     `json:"-"`
 	Time    time.Time `json:"time"`
+	// Timing is set by clients created with httpclient.WithTiming.
+	Timing *httptiming.Timing `json:"timing,omitempty"`
 }
 
 // Error prints the error struct
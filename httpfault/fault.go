@@ -0,0 +1,201 @@
+package httpfault
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type faultKind int
+
+const (
+	latencyFault faultKind = iota
+	connectionFault
+	timeoutFault
+	statusFault
+	truncateFault
+	slowBodyFault
+)
+
+var faultNames = map[faultKind]string{
+	latencyFault:    "latency",
+	connectionFault: "connection",
+	timeoutFault:    "timeout",
+	statusFault:     "status",
+	truncateFault:   "truncate",
+	slowBodyFault:   "slow_body",
+}
+
+// Fault is what a rule injects. Build one with Latency, ConnectionError,
+// Timeout, Status, TruncatedBody or SlowBody.
+type Fault struct {
+	kind     faultKind
+	duration time.Duration
+	status   int
+	body     string
+	bytes    int
+}
+
+// Name is the key the fault is counted under in Stats.
+func (fault Fault) Name() string {
+	return faultNames[fault.kind]
+}
+
+// Latency delays the request, then lets it through.
+func Latency(delay time.Duration) Fault {
+	return Fault{kind: latencyFault, duration: delay}
+}
+
+// ConnectionError fails the request the way a refused dial does.
+func ConnectionError() Fault {
+	return Fault{kind: connectionFault}
+}
+
+// Timeout waits for after, or until the request is cancelled, and then fails
+// with context.DeadlineExceeded.
+func Timeout(after time.Duration) Fault {
+	return Fault{kind: timeoutFault, duration: after}
+}
+
+// Status answers with the status code and body without sending the request.
+func Status(status int, body string) Fault {
+	return Fault{kind: statusFault, status: status, body: body}
+}
+
+// TruncatedBody cuts the real response body off after n bytes with
+// io.ErrUnexpectedEOF.
+func TruncatedBody(n int) Fault {
+	return Fault{kind: truncateFault, bytes: n}
+}
+
+// SlowBody drips the real response body chunk bytes at a time, waiting
+// interval before each chunk.
+func SlowBody(chunk int, interval time.Duration) Fault {
+	if chunk <= 0 {
+		chunk = 1
+	}
+	return Fault{kind: slowBodyFault, bytes: chunk, duration: interval}
+}
+
+// terminal reports whether the fault answers instead of the real transport.
+func (fault Fault) terminal() bool {
+	return fault.kind == connectionFault || fault.kind == timeoutFault || fault.kind == statusFault
+}
+
+// before runs ahead of the real request. A non-nil response or error ends
+// the request there.
+func (fault Fault) before(request *http.Request) (*http.Response, error) {
+	switch fault.kind {
+	case latencyFault:
+		if err := sleep(request.Context(), fault.duration); err != nil {
+			return nil, urlError(request, err)
+		}
+	case connectionFault:
+		return nil, urlError(request, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED})
+	case timeoutFault:
+		if err := sleep(request.Context(), fault.duration); err != nil {
+			return nil, urlError(request, err)
+		}
+		return nil, urlError(request, context.DeadlineExceeded)
+	case statusFault:
+		return &http.Response{
+			Status:        http.StatusText(fault.status),
+			StatusCode:    fault.status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader(fault.body)),
+			ContentLength: int64(len(fault.body)),
+			Request:       request,
+		}, nil
+	}
+	return nil, nil
+}
+
+// after wraps the body of the real response.
+func (fault Fault) after(request *http.Request, response *http.Response) {
+	switch fault.kind {
+	case truncateFault:
+		response.Body = &truncatedBody{ReadCloser: response.Body, remaining: fault.bytes}
+		response.ContentLength = -1
+	case slowBodyFault:
+		response.Body = &slowBody{
+			ReadCloser: response.Body,
+			ctx:        request.Context(),
+			chunk:      fault.bytes,
+			interval:   fault.duration,
+		}
+	}
+}
+
+func sleep(ctx context.Context, duration time.Duration) error {
+	if duration <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// urlError wraps err like http.Client does, so callers see the same error
+// shape as for real failures.
+func urlError(request *http.Request, err error) error {
+	method := request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	return &url.Error{
+		Op:  method[:1] + strings.ToLower(method[1:]),
+		URL: request.URL.String(),
+		Err: err,
+	}
+}
+
+type truncatedBody struct {
+	io.ReadCloser
+	remaining int
+}
+
+// Read ...
+func (body *truncatedBody) Read(p []byte) (int, error) {
+	if body.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if len(p) > body.remaining {
+		p = p[:body.remaining]
+	}
+
+	n, err := body.ReadCloser.Read(p)
+	body.remaining -= n
+	return n, err
+}
+
+type slowBody struct {
+	io.ReadCloser
+	ctx      context.Context
+	chunk    int
+	interval time.Duration
+}
+
+// Read ...
+func (body *slowBody) Read(p []byte) (int, error) {
+	if err := sleep(body.ctx, body.interval); err != nil {
+		return 0, err
+	}
+	if len(p) > body.chunk {
+		p = p[:body.chunk]
+	}
+	return body.ReadCloser.Read(p)
+}
@@ -0,0 +1,124 @@
+package httpfault
+
+import (
+	"math/rand"
+	"net/http"
+	"path"
+	"sync"
+)
+
+// Requester ...
+type Requester interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Rule injects Fault into a share of the matching requests.
+type Rule struct {
+	// Hosts and Paths are path.Match patterns, such as "*.rain.us" or
+	// "/users/*". Empty lists match everything.
+	Hosts []string
+	Paths []string
+	// Probability is between 0 and 1.
+	Probability float64
+	Fault       Fault
+}
+
+func (rule Rule) matches(request *http.Request) bool {
+	return matchAny(rule.Hosts, request.URL.Hostname()) && matchAny(rule.Paths, request.URL.Path)
+}
+
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Configs ...
+type Configs struct {
+	// Seed makes the injected faults reproducible for a given sequence of
+	// requests.
+	Seed  int64
+	Rules []Rule
+}
+
+// NewInjector wraps requester, typically an http.Client, so that
+// HTTPRequester sees the injected faults as if they came from the network.
+func NewInjector(requester Requester, configs Configs) *Injector {
+	return &Injector{
+		requester: requester,
+		rules:     configs.Rules,
+		random:    rand.New(rand.NewSource(configs.Seed)),
+		stats:     map[string]int64{},
+	}
+}
+
+// Injector ...
+type Injector struct {
+	requester Requester
+	rules     []Rule
+
+	mutex  sync.Mutex
+	random *rand.Rand
+	stats  map[string]int64
+}
+
+// Stats returns how many times each fault was injected, by Fault.Name.
+func (injector *Injector) Stats() map[string]int64 {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+
+	stats := make(map[string]int64, len(injector.stats))
+	for name, count := range injector.stats {
+		stats[name] = count
+	}
+	return stats
+}
+
+// pick rolls every matching rule. Latency and body faults accumulate; the
+// first terminal fault ends the list since the request never gets further.
+func (injector *Injector) pick(request *http.Request) []Fault {
+	injector.mutex.Lock()
+	defer injector.mutex.Unlock()
+
+	var faults []Fault
+	for _, rule := range injector.rules {
+		if !rule.matches(request) || injector.random.Float64() >= rule.Probability {
+			continue
+		}
+
+		injector.stats[rule.Fault.Name()]++
+		faults = append(faults, rule.Fault)
+		if rule.Fault.terminal() {
+			break
+		}
+	}
+	return faults
+}
+
+// Do ...
+func (injector *Injector) Do(request *http.Request) (*http.Response, error) {
+	faults := injector.pick(request)
+
+	for _, fault := range faults {
+		response, err := fault.before(request)
+		if response != nil || err != nil {
+			return response, err
+		}
+	}
+
+	response, err := injector.requester.Do(request)
+	if err != nil {
+		return response, err
+	}
+
+	for _, fault := range faults {
+		fault.after(request, response)
+	}
+	return response, nil
+}
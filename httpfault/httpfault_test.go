@@ -0,0 +1,124 @@
+package httpfault
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httperror"
+	"github.com/ttanik/http-client/httprequester"
+)
+
+func newTestServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"name":"rain"}`))
+	}))
+}
+
+func execute(t *testing.T, injector *Injector, url string) (*http.Response, *httperror.HTTPError) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.NoError(t, err)
+
+	return httprequester.NewHTTPRequester(injector, httpdecoder.NewHTTPDecoder()).ExecuteRequest(request)
+}
+
+func always(fault Fault) Configs {
+	return Configs{Rules: []Rule{{Probability: 1, Fault: fault}}}
+}
+
+func TestInjector_HTTPRequesterErrorPaths(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	_, failure := execute(t, NewInjector(http.DefaultClient, always(ConnectionError())), server.URL)
+	assert.Equal(t, http.StatusInternalServerError, failure.Status)
+	assert.ErrorIs(t, failure.Err, syscall.ECONNREFUSED)
+
+	_, failure = execute(t, NewInjector(http.DefaultClient, always(Timeout(0))), server.URL)
+	assert.Equal(t, http.StatusGatewayTimeout, failure.Status)
+
+	_, failure = execute(t, NewInjector(http.DefaultClient, always(Status(http.StatusInternalServerError, `{"message":"boom"}`))), server.URL)
+	assert.Equal(t, http.StatusFailedDependency, failure.Status)
+	assert.Equal(t, "dependency failed", failure.Message)
+
+	response, failure := execute(t, NewInjector(http.DefaultClient, always(Status(http.StatusTooManyRequests, ""))), server.URL)
+	assert.Nil(t, failure)
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+}
+
+func TestInjector_TruncatedBody(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	response, failure := execute(t, NewInjector(http.DefaultClient, always(TruncatedBody(5))), server.URL)
+	assert.Nil(t, failure)
+
+	var target struct{ Name string }
+	httpError := httpdecoder.NewHTTPDecoder().DecodeResponseBody(context.Background(), response, &target)
+	assert.Equal(t, "error decoding response body", httpError.Message)
+}
+
+func TestInjector_SlowBodyAndLatency(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	injector := NewInjector(http.DefaultClient, Configs{Rules: []Rule{
+		{Probability: 1, Fault: Latency(20 * time.Millisecond)},
+		{Probability: 1, Fault: SlowBody(5, 5*time.Millisecond)},
+	}})
+	start := time.Now()
+	response, failure := execute(t, injector, server.URL)
+	assert.Nil(t, failure)
+	body, err := io.ReadAll(response.Body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"rain"}`, string(body))
+	assert.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+	assert.Equal(t, map[string]int64{"latency": 1, "slow_body": 1}, injector.Stats())
+}
+
+func TestInjector_LatencyRespectsCancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://rain.us", nil)
+
+	_, err := NewInjector(http.DefaultClient, always(Latency(time.Second))).Do(request)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestInjector_ScopedAndSeeded(t *testing.T) {
+	server := newTestServer()
+	defer server.Close()
+
+	configs := Configs{Seed: 42, Rules: []Rule{{
+		Hosts:       []string{"127.0.0.*"},
+		Paths:       []string{"/users/*"},
+		Probability: 0.5,
+		Fault:       Status(http.StatusServiceUnavailable, ""),
+	}}}
+	run := func() []int {
+		injector := NewInjector(http.DefaultClient, configs)
+		var statuses []int
+		for i := 0; i < 20; i++ {
+			response, _ := execute(t, injector, server.URL+"/users/1")
+			statuses = append(statuses, response.StatusCode)
+			_ = response.Body.Close()
+		}
+		response, _ := execute(t, injector, server.URL+"/orders/1")
+		statuses = append(statuses, response.StatusCode)
+		return statuses
+	}
+
+	first := run()
+	assert.Equal(t, first, run())
+	assert.Contains(t, first, http.StatusServiceUnavailable)
+	assert.Contains(t, first[:20], http.StatusOK)
+	assert.Equal(t, http.StatusOK, first[20])
+}
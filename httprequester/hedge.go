@@ -0,0 +1,276 @@
+package httprequester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ttanik/http-client/httperror"
+	"github.com/ttanik/http-client/httptiming"
+)
+
+const (
+	defaultHedgeDelay      = 100 * time.Millisecond
+	defaultHedgeMinSamples = 20
+	defaultHedgeWindow     = 256
+)
+
+// HedgePolicy configures hedged requests. When an idempotent request has not
+// answered within the hedge delay a second copy is fired and the first
+// successful response wins.
+type HedgePolicy struct {
+	// Delay is the static hedge delay. It is also used while there are not
+	// enough latency samples to compute Percentile.
+	Delay time.Duration
+	// Percentile, when set (0 < Percentile < 1), derives the delay from the
+	// observed latencies, e.g. 0.95 hedges requests slower than the p95.
+	Percentile float64
+	// MinSamples is the number of samples needed before Percentile is used.
+	MinSamples int
+	// Window is the number of recent latencies kept for Percentile.
+	Window int
+	// MaxHedges caps the number of extra copies fired for a single request.
+	MaxHedges int
+}
+
+// HedgeStats ...
+type HedgeStats struct {
+	// Requests is the number of requests eligible for hedging.
+	Requests int64
+	// Hedges is the number of extra copies fired.
+	Hedges int64
+	// Wins is the number of requests answered by a hedge instead of the
+	// original attempt.
+	Wins int64
+}
+
+type hedgeResult struct {
+	attempt  int
+	response *http.Response
+	err      *httperror.HTTPError
+	latency  time.Duration
+}
+
+type hedger struct {
+	policy HedgePolicy
+
+	requests atomic.Int64
+	hedges   atomic.Int64
+	wins     atomic.Int64
+
+	mutex     sync.Mutex
+	latencies []time.Duration
+	next      int
+}
+
+func newHedger(policy HedgePolicy) *hedger {
+	if policy.Delay <= 0 {
+		policy.Delay = defaultHedgeDelay
+	}
+	if policy.MinSamples <= 0 {
+		policy.MinSamples = defaultHedgeMinSamples
+	}
+	if policy.Window <= 0 {
+		policy.Window = defaultHedgeWindow
+	}
+	if policy.MaxHedges <= 0 {
+		policy.MaxHedges = 1
+	}
+
+	return &hedger{
+		policy:    policy,
+		latencies: make([]time.Duration, 0, policy.Window),
+	}
+}
+
+func (h *hedger) canHedge(request *http.Request) bool {
+	if !isIdempotent(request.Method) {
+		return false
+	}
+
+	return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+}
+
+func (h *hedger) stats() HedgeStats {
+	return HedgeStats{
+		Requests: h.requests.Load(),
+		Hedges:   h.hedges.Load(),
+		Wins:     h.wins.Load(),
+	}
+}
+
+func (h *hedger) observe(latency time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.latencies) < h.policy.Window {
+		h.latencies = append(h.latencies, latency)
+		return
+	}
+	h.latencies[h.next] = latency
+	h.next = (h.next + 1) % h.policy.Window
+}
+
+func (h *hedger) delay() time.Duration {
+	if h.policy.Percentile <= 0 || h.policy.Percentile >= 1 {
+		return h.policy.Delay
+	}
+
+	h.mutex.Lock()
+	if len(h.latencies) < h.policy.MinSamples {
+		h.mutex.Unlock()
+		return h.policy.Delay
+	}
+	samples := make([]time.Duration, len(h.latencies))
+	copy(samples, h.latencies)
+	h.mutex.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	index := int(h.policy.Percentile * float64(len(samples)))
+	if index >= len(samples) {
+		index = len(samples) - 1
+	}
+
+	return samples[index]
+}
+
+func (h *hedger) execute(
+	request *http.Request,
+	execute func(request *http.Request) (*http.Response, *httperror.HTTPError),
+) (*http.Response, *httperror.HTTPError) {
+	h.requests.Add(1)
+
+	results := make(chan hedgeResult, h.policy.MaxHedges+1)
+	cancels := make([]context.CancelFunc, 0, h.policy.MaxHedges+1)
+	// Traced attempts record their phases apart, see httptiming.WithAttempt.
+	traced := httptiming.FromContext(request.Context()) != nil
+	recorders := make([]*httptiming.Recorder, 0, h.policy.MaxHedges+1)
+	cancelAll := func(except int) {
+		for attempt, cancel := range cancels {
+			if attempt != except {
+				cancel()
+			}
+		}
+	}
+
+	launch := func() *httperror.HTTPError {
+		attempt := len(cancels)
+		ctx, cancel := context.WithCancel(request.Context())
+		var recorder *httptiming.Recorder
+		if traced {
+			ctx, recorder = httptiming.WithAttempt(ctx)
+		}
+		attemptRequest := request.Clone(ctx)
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				cancel()
+				return &httperror.HTTPError{
+					Status:  http.StatusInternalServerError,
+					Message: "error copying request body",
+					Err:     err,
+				}
+			}
+			attemptRequest.Body = body
+		}
+		cancels = append(cancels, cancel)
+		recorders = append(recorders, recorder)
+
+		go func() {
+			start := time.Now()
+			response, err := execute(attemptRequest)
+			results <- hedgeResult{attempt: attempt, response: response, err: err, latency: time.Since(start)}
+		}()
+		return nil
+	}
+
+	if err := launch(); err != nil {
+		return nil, err
+	}
+	inFlight := 1
+
+	timer := time.NewTimer(h.delay())
+	defer timer.Stop()
+
+	var lastErr *httperror.HTTPError
+	for {
+		select {
+		case result := <-results:
+			inFlight--
+			if result.err == nil {
+				h.observe(result.latency)
+				if result.attempt > 0 {
+					h.wins.Add(1)
+				}
+				recorders[result.attempt].Commit()
+				cancelAll(result.attempt)
+				go drainHedges(results, inFlight)
+				return withCancelOnClose(result.response, cancels[result.attempt]), nil
+			}
+
+			lastErr = result.err
+			if inFlight == 0 {
+				cancelAll(-1)
+				return nil, lastErr
+			}
+		case <-timer.C:
+			if len(cancels) > h.policy.MaxHedges {
+				continue
+			}
+			if err := launch(); err != nil {
+				continue
+			}
+			inFlight++
+			h.hedges.Add(1)
+			timer.Reset(h.delay())
+		}
+	}
+}
+
+// drainHedges waits for the cancelled attempts and releases their connections.
+func drainHedges(results <-chan hedgeResult, pending int) {
+	for i := 0; i < pending; i++ {
+		result := <-results
+		if result.response == nil || result.response.Body == nil {
+			continue
+		}
+		_, _ = io.Copy(io.Discard, result.response.Body)
+		_ = result.response.Body.Close()
+	}
+}
+
+// withCancelOnClose keeps the winning attempt's context alive until its body
+// is closed.
+func withCancelOnClose(response *http.Response, cancel context.CancelFunc) *http.Response {
+	if response == nil || response.Body == nil {
+		cancel()
+		return response
+	}
+
+	response.Body = &cancelOnCloseBody{ReadCloser: response.Body, cancel: cancel}
+	return response
+}
+
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close ...
+func (body *cancelOnCloseBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package httprequester
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httprequester/mocks"
+)
+
+type requesterFunc func(request *http.Request) (*http.Response, error)
+
+func (f requesterFunc) Do(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func newTestRequest(method string) *http.Request {
+	request, _ := http.NewRequest(method, "http://rain.us/test", nil)
+	return request
+}
+
+func TestHTTPRequester_ExecuteRequest_HedgeWins(t *testing.T) {
+	var calls atomic.Int64
+	requester := requesterFunc(func(request *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			<-request.Context().Done()
+			return nil, request.Context().Err()
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("hedge"))}, nil
+	})
+
+	httpRequester := NewHTTPRequester(requester, new(mocks.Decoder)).
+		WithHedging(HedgePolicy{Delay: 10 * time.Millisecond})
+	response, httpError := httpRequester.ExecuteRequest(newTestRequest(http.MethodGet))
+
+	assert.Nil(t, httpError)
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, "hedge", string(body))
+	assert.NoError(t, response.Body.Close())
+	assert.Equal(t, HedgeStats{Requests: 1, Hedges: 1, Wins: 1}, httpRequester.HedgeStats())
+}
+
+func TestHTTPRequester_ExecuteRequest_NoHedgeWhenFast(t *testing.T) {
+	var calls atomic.Int64
+	requester := requesterFunc(func(request *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+
+	httpRequester := NewHTTPRequester(requester, new(mocks.Decoder)).
+		WithHedging(HedgePolicy{Delay: time.Second})
+	response, httpError := httpRequester.ExecuteRequest(newTestRequest(http.MethodGet))
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(1), calls.Load())
+	assert.Equal(t, HedgeStats{Requests: 1}, httpRequester.HedgeStats())
+}
+
+func TestHTTPRequester_ExecuteRequest_MaxHedges(t *testing.T) {
+	var calls atomic.Int64
+	requester := requesterFunc(func(request *http.Request) (*http.Response, error) {
+		if calls.Add(1) < 3 {
+			<-request.Context().Done()
+			return nil, request.Context().Err()
+		}
+		time.Sleep(20 * time.Millisecond)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+
+	httpRequester := NewHTTPRequester(requester, new(mocks.Decoder)).
+		WithHedging(HedgePolicy{Delay: 5 * time.Millisecond, MaxHedges: 2})
+	response, httpError := httpRequester.ExecuteRequest(newTestRequest(http.MethodGet))
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(3), calls.Load())
+	assert.Equal(t, int64(2), httpRequester.HedgeStats().Hedges)
+}
+
+func TestHTTPRequester_ExecuteRequest_NoHedgeForPost(t *testing.T) {
+	var calls atomic.Int64
+	requester := requesterFunc(func(request *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+
+	httpRequester := NewHTTPRequester(requester, new(mocks.Decoder)).
+		WithHedging(HedgePolicy{Delay: time.Millisecond})
+	_, httpError := httpRequester.ExecuteRequest(newTestRequest(http.MethodPost))
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, int64(1), calls.Load())
+	assert.Equal(t, HedgeStats{}, httpRequester.HedgeStats())
+}
+
+func TestHedger_Delay_Percentile(t *testing.T) {
+	hedger := newHedger(HedgePolicy{Delay: time.Second, Percentile: 0.9, MinSamples: 10})
+	assert.Equal(t, time.Second, hedger.delay())
+
+	for i := 1; i <= 10; i++ {
+		hedger.observe(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 10*time.Millisecond, hedger.delay())
+}
@@ -18,7 +18,10 @@ func NewHTTPRequester(
 	requester Requester,
 	decoder Decoder,
 ) *HTTPRequester {
-	return &HTTPRequester{requester, decoder}
+	return &HTTPRequester{
+		requestExecutioner: requester,
+		decoder:            decoder,
+	}
 }
 
 // Decoder ...
@@ -30,11 +33,49 @@ type Decoder interface {
 type HTTPRequester struct {
 	requestExecutioner Requester
 	decoder            Decoder
+	hedger             *hedger
+	retryPolicy        *RetryPolicy
+}
+
+// WithHedging enables hedged requests for idempotent methods.
+func (requester *HTTPRequester) WithHedging(policy HedgePolicy) *HTTPRequester {
+	requester.hedger = newHedger(policy)
+	return requester
+}
+
+// WithRetry retries failed requests. Hedged attempts count as a single
+// attempt.
+func (requester *HTTPRequester) WithRetry(policy RetryPolicy) *HTTPRequester {
+	requester.retryPolicy = newRetryPolicy(policy)
+	return requester
+}
+
+// HedgeStats returns how often hedging fired and won.
+func (requester *HTTPRequester) HedgeStats() HedgeStats {
+	if requester.hedger == nil {
+		return HedgeStats{}
+	}
+	return requester.hedger.stats()
 }
 
 // ExecuteRequest ...
 func (requester *HTTPRequester) ExecuteRequest(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	if requester.retryPolicy != nil {
+		return requester.retryPolicy.execute(request, requester.executeAttempt)
+	}
+
+	return requester.executeAttempt(request)
+}
+
+func (requester *HTTPRequester) executeAttempt(request *http.Request) (*http.Response, *httperror.HTTPError) {
+	if requester.hedger != nil && requester.hedger.canHedge(request) {
+		return requester.hedger.execute(request, requester.executeRequest)
+	}
+
+	return requester.executeRequest(request)
+}
 
+func (requester *HTTPRequester) executeRequest(request *http.Request) (*http.Response, *httperror.HTTPError) {
 	response, err := requester.requestExecutioner.Do(request)
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
@@ -0,0 +1,144 @@
+package httprequester
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ttanik/http-client/httperror"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 5 * time.Second
+)
+
+// RetryPolicy retries failed requests with exponential backoff and full
+// jitter. Requests with a non-idempotent method (POST, PATCH) are only
+// retried when they carry an Idempotency-Key header.
+type RetryPolicy struct {
+	// MaxAttempts includes the first attempt.
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	// RetryableStatus lists response status codes worth retrying. Errors with
+	// a 5xx status or 424 (a failed dependency) are always retried.
+	RetryableStatus []int
+}
+
+func newRetryPolicy(policy RetryPolicy) *RetryPolicy {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = defaultRetryBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if policy.RetryableStatus == nil {
+		policy.RetryableStatus = []int{
+			http.StatusTooManyRequests,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		}
+	}
+	return &policy
+}
+
+// canRetry reports whether request may be sent more than once.
+func (policy *RetryPolicy) canRetry(request *http.Request) bool {
+	if !isIdempotent(request.Method) && request.Header.Get(idempotencyKeyHeader) == "" {
+		return false
+	}
+
+	return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+}
+
+func (policy *RetryPolicy) execute(
+	request *http.Request,
+	execute func(request *http.Request) (*http.Response, *httperror.HTTPError),
+) (*http.Response, *httperror.HTTPError) {
+	if policy.MaxAttempts == 1 || !policy.canRetry(request) {
+		return execute(request)
+	}
+
+	attemptRequest := request
+	for attempt := 1; ; attempt++ {
+		response, httpError := execute(attemptRequest)
+		if attempt >= policy.MaxAttempts || !policy.shouldRetry(response, httpError) {
+			return response, httpError
+		}
+
+		wait := policy.backoff(attempt, response)
+		if response != nil && response.Body != nil {
+			_, _ = io.Copy(io.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			if response != nil {
+				return nil, &httperror.HTTPError{
+					Status:  http.StatusGatewayTimeout,
+					Message: "request timed out",
+					Err:     request.Context().Err(),
+				}
+			}
+			return nil, httpError
+		case <-timer.C:
+		}
+
+		attemptRequest = request.Clone(request.Context())
+		if request.GetBody != nil {
+			body, err := request.GetBody()
+			if err != nil {
+				return nil, &httperror.HTTPError{
+					Status:  http.StatusInternalServerError,
+					Message: "error copying request body",
+					Err:     err,
+				}
+			}
+			attemptRequest.Body = body
+		}
+	}
+}
+
+func (policy *RetryPolicy) shouldRetry(response *http.Response, httpError *httperror.HTTPError) bool {
+	if httpError != nil {
+		return httpError.Status >= http.StatusInternalServerError || httpError.Status == http.StatusFailedDependency
+	}
+
+	for _, status := range policy.RetryableStatus {
+		if response.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff honours Retry-After in seconds, capped by MaxBackoff.
+func (policy *RetryPolicy) backoff(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			wait := time.Duration(seconds) * time.Second
+			if wait > policy.MaxBackoff {
+				wait = policy.MaxBackoff
+			}
+			return wait
+		}
+	}
+
+	ceiling := policy.Backoff << (attempt - 1)
+	if ceiling <= 0 || ceiling > policy.MaxBackoff {
+		ceiling = policy.MaxBackoff
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
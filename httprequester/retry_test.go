@@ -0,0 +1,110 @@
+package httprequester
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httprequester/mocks"
+)
+
+func flakyRequester(failures int64, bodies *[]string) (requesterFunc, *atomic.Int64) {
+	var calls atomic.Int64
+	return requesterFunc(func(request *http.Request) (*http.Response, error) {
+		if bodies != nil && request.Body != nil {
+			body, _ := io.ReadAll(request.Body)
+			*bodies = append(*bodies, string(body)+"|"+request.Header.Get(idempotencyKeyHeader))
+		}
+		if calls.Add(1) <= failures {
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}), &calls
+}
+
+func TestHTTPRequester_ExecuteRequest_RetriesIdempotentMethods(t *testing.T) {
+	requester, calls := flakyRequester(2, nil)
+
+	response, httpError := NewHTTPRequester(requester, new(mocks.Decoder)).
+		WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}).
+		ExecuteRequest(newTestRequest(http.MethodGet))
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(3), calls.Load())
+}
+
+func TestHTTPRequester_ExecuteRequest_StopsAfterMaxAttempts(t *testing.T) {
+	requester, calls := flakyRequester(5, nil)
+
+	response, httpError := NewHTTPRequester(requester, new(mocks.Decoder)).
+		WithRetry(RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}).
+		ExecuteRequest(newTestRequest(http.MethodGet))
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Equal(t, int64(2), calls.Load())
+}
+
+func TestHTTPRequester_ExecuteRequest_RetriesTransportErrors(t *testing.T) {
+	var calls atomic.Int64
+	requester := requesterFunc(func(request *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+
+	response, httpError := NewHTTPRequester(requester, new(mocks.Decoder)).
+		WithRetry(RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}).
+		ExecuteRequest(newTestRequest(http.MethodDelete))
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestHTTPRequester_ExecuteRequest_DoesNotRetryPostWithoutKey(t *testing.T) {
+	requester, calls := flakyRequester(1, nil)
+	request, _ := http.NewRequest(http.MethodPost, "http://rain.us/test", strings.NewReader("body"))
+
+	response, httpError := NewHTTPRequester(requester, new(mocks.Decoder)).
+		WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}).
+		ExecuteRequest(request)
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Equal(t, int64(1), calls.Load())
+}
+
+func TestHTTPRequester_ExecuteRequest_RetriesPostWithKey(t *testing.T) {
+	var bodies []string
+	requester, calls := flakyRequester(1, &bodies)
+	request, _ := http.NewRequest(http.MethodPost, "http://rain.us/test", strings.NewReader("body"))
+	request.Header.Set(idempotencyKeyHeader, "key-1")
+
+	response, httpError := NewHTTPRequester(requester, new(mocks.Decoder)).
+		WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}).
+		ExecuteRequest(request)
+
+	assert.Nil(t, httpError)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(2), calls.Load())
+	assert.Equal(t, []string{"body|key-1", "body|key-1"}, bodies)
+}
+
+func TestRetryPolicy_Backoff_RetryAfter(t *testing.T) {
+	policy := newRetryPolicy(RetryPolicy{MaxAttempts: 2, MaxBackoff: 3 * time.Second})
+	response := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	assert.Equal(t, 2*time.Second, policy.backoff(1, response))
+
+	response.Header.Set("Retry-After", "60")
+	assert.Equal(t, 3*time.Second, policy.backoff(1, response))
+
+	for attempt := 1; attempt < 10; attempt++ {
+		assert.LessOrEqual(t, policy.backoff(attempt, nil), 3*time.Second)
+	}
+}
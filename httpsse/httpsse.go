@@ -0,0 +1,230 @@
+package httpsse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httperror"
+)
+
+const (
+	defaultRetryDelay  = 3 * time.Second
+	defaultMaxLineSize = 1 << 20
+)
+
+// Configs ...
+type Configs struct {
+	Headers map[string]string
+	// RetryDelay is used until the server advises one with a retry field.
+	RetryDelay time.Duration
+	// MaxRetries caps consecutive failed reconnections. Zero retries forever.
+	MaxRetries int
+	// LastEventID resumes a stream from a known event.
+	LastEventID string
+	MaxLineSize int
+}
+
+// NewReader ...
+func NewReader(client *httpclient.Client, endpoint string, configs Configs) *Reader {
+	if configs.RetryDelay <= 0 {
+		configs.RetryDelay = defaultRetryDelay
+	}
+	if configs.MaxLineSize <= 0 {
+		configs.MaxLineSize = defaultMaxLineSize
+	}
+
+	return &Reader{
+		client:   client,
+		endpoint: endpoint,
+		configs:  configs,
+	}
+}
+
+// Reader consumes a Server-Sent Events endpoint and reconnects with
+// Last-Event-ID when the connection drops.
+type Reader struct {
+	client   *httpclient.Client
+	endpoint string
+	configs  Configs
+
+	mutex sync.Mutex
+	err   *httperror.HTTPError
+}
+
+// Events streams events until ctx is done or the stream fails for good. The
+// channel is closed afterwards and Err reports why.
+func (reader *Reader) Events(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+	go reader.run(ctx, events)
+	return events
+}
+
+// Err returns the error that ended the stream, nil if ctx ended it.
+func (reader *Reader) Err() *httperror.HTTPError {
+	reader.mutex.Lock()
+	defer reader.mutex.Unlock()
+	return reader.err
+}
+
+func (reader *Reader) run(ctx context.Context, events chan<- Event) {
+	defer close(events)
+
+	lastEventID := reader.configs.LastEventID
+	retryDelay := reader.configs.RetryDelay
+	var advisedRetry time.Duration
+	failures := 0
+
+	for {
+		response, httpError := reader.connect(ctx, lastEventID)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if httpError == nil {
+			parser := newParser(response.Body, lastEventID, advisedRetry, reader.configs.MaxLineSize)
+			delivered, ok := reader.deliver(ctx, parser, events)
+			_ = response.Body.Close()
+
+			lastEventID = parser.lastEventID
+			if parser.retry > 0 {
+				advisedRetry = parser.retry
+				retryDelay = parser.retry
+			}
+			if !ok || ctx.Err() != nil {
+				return
+			}
+
+			err := parser.err()
+			if errors.Is(err, bufio.ErrTooLong) {
+				// Reconnecting would only receive the same line again.
+				reader.fail(&httperror.HTTPError{
+					Status:  http.StatusBadGateway,
+					Message: "event stream line too long",
+					Err:     err,
+					Time:    time.Now(),
+				})
+				return
+			}
+			if err == nil || delivered > 0 {
+				failures = 0
+			}
+			if err != nil {
+				httpError = &httperror.HTTPError{
+					Status:  http.StatusInternalServerError,
+					Message: "error reading event stream",
+					Err:     err,
+					Time:    time.Now(),
+				}
+			}
+		}
+
+		if httpError != nil {
+			if httpError.Status == http.StatusNoContent {
+				return
+			}
+			if !isRetryable(httpError) {
+				reader.fail(httpError)
+				return
+			}
+
+			failures++
+			if reader.configs.MaxRetries > 0 && failures > reader.configs.MaxRetries {
+				reader.fail(httpError)
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retryDelay):
+		}
+	}
+}
+
+// deliver forwards the events of parser. It returns how many it delivered
+// and false when ctx ended the stream.
+func (reader *Reader) deliver(ctx context.Context, parser *parser, events chan<- Event) (int, bool) {
+	delivered := 0
+	for {
+		event, ok := parser.next()
+		if !ok {
+			return delivered, true
+		}
+
+		select {
+		case events <- event:
+			delivered++
+		case <-ctx.Done():
+			return delivered, false
+		}
+	}
+}
+
+func (reader *Reader) connect(ctx context.Context, lastEventID string) (*http.Response, *httperror.HTTPError) {
+	headers := map[string]string{
+		"Accept":        "text/event-stream",
+		"Cache-Control": "no-cache",
+	}
+	for key, value := range reader.configs.Headers {
+		headers[key] = value
+	}
+	if lastEventID != "" {
+		headers["Last-Event-ID"] = lastEventID
+	}
+
+	request, httpError := reader.client.NewRequestBuilder(ctx).
+		WithEndpoint(reader.endpoint).
+		WithMethod(http.MethodGet).
+		WithHeaders(headers).
+		Build()
+	if httpError != nil {
+		return nil, httpError
+	}
+
+	response, httpError := reader.client.ExecuteRequest(request)
+	if httpError != nil {
+		return nil, httpError
+	}
+
+	// A 204 tells the client to stop reconnecting; any other status or content
+	// type fails the connection, as in the EventSource specification.
+	if response.StatusCode != http.StatusOK {
+		_ = response.Body.Close()
+		return nil, &httperror.HTTPError{
+			Status:  response.StatusCode,
+			Message: "unexpected event stream status",
+			Time:    time.Now(),
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		_ = response.Body.Close()
+		return nil, &httperror.HTTPError{
+			Status:  http.StatusUnsupportedMediaType,
+			Message: "unexpected event stream content type",
+			Time:    time.Now(),
+		}
+	}
+
+	return response, nil
+}
+
+func (reader *Reader) fail(httpError *httperror.HTTPError) {
+	reader.mutex.Lock()
+	defer reader.mutex.Unlock()
+	reader.err = httpError
+}
+
+// isRetryable separates network and server failures, which are retried, from
+// responses that end the stream.
+func isRetryable(httpError *httperror.HTTPError) bool {
+	return httpError.Status >= http.StatusInternalServerError || httpError.Status == http.StatusFailedDependency
+}
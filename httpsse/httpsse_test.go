@@ -0,0 +1,159 @@
+package httpsse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ttanik/http-client/httpclient"
+	"github.com/ttanik/http-client/httpdecoder"
+	"github.com/ttanik/http-client/httpmarshal"
+	"github.com/ttanik/http-client/httprequester"
+)
+
+func newTestClient() *httpclient.Client {
+	requester := httprequester.NewHTTPRequester(http.DefaultClient, httpdecoder.NewHTTPDecoder())
+	return httpclient.NewHTTPClient(httpclient.WithRequester(requester), httpclient.WithMarshaller(httpmarshal.NewHTTPMarshal()))
+}
+
+func TestReader_Events_Reconnects(t *testing.T) {
+	var mutex sync.Mutex
+	var lastEventIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		lastEventIDs = append(lastEventIDs, request.Header.Get("Last-Event-ID"))
+		connection := len(lastEventIDs)
+		mutex.Unlock()
+
+		writer.Header().Set("Content-Type", "text/event-stream")
+		if connection == 1 {
+			fmt.Fprint(writer, "retry: 10\nid: 1\ndata: first\n\n")
+			return
+		}
+		fmt.Fprint(writer, "id: 2\nevent: update\ndata: second\n\n")
+		writer.(http.Flusher).Flush()
+		<-request.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader := NewReader(newTestClient(), server.URL, Configs{RetryDelay: time.Minute})
+	events := reader.Events(ctx)
+
+	first := <-events
+	second := <-events
+	cancel()
+	_, open := <-events
+
+	assert.Equal(t, Event{ID: "1", Event: "message", Data: "first", Retry: 10 * time.Millisecond}, first)
+	assert.Equal(t, Event{ID: "2", Event: "update", Data: "second", Retry: 10 * time.Millisecond}, second)
+	assert.False(t, open)
+	assert.Nil(t, reader.Err())
+	mutex.Lock()
+	assert.Equal(t, []string{"", "1"}, lastEventIDs)
+	mutex.Unlock()
+}
+
+func TestReader_Events_NoContentStops(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	reader := NewReader(newTestClient(), server.URL, Configs{})
+	_, open := <-reader.Events(context.Background())
+
+	assert.False(t, open)
+	assert.Nil(t, reader.Err())
+}
+
+func TestReader_Events_ClientErrorFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	reader := NewReader(newTestClient(), server.URL, Configs{})
+	_, open := <-reader.Events(context.Background())
+
+	assert.False(t, open)
+	assert.Equal(t, http.StatusUnauthorized, reader.Err().Status)
+}
+
+func TestReader_Events_MaxRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	reader := NewReader(newTestClient(), server.URL, Configs{RetryDelay: time.Millisecond, MaxRetries: 2})
+	_, open := <-reader.Events(context.Background())
+
+	assert.False(t, open)
+	assert.Equal(t, http.StatusServiceUnavailable, reader.Err().Status)
+}
+
+func TestReader_Events_WrongContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+	}))
+	defer server.Close()
+
+	reader := NewReader(newTestClient(), server.URL, Configs{})
+	_, open := <-reader.Events(context.Background())
+
+	assert.False(t, open)
+	assert.Equal(t, http.StatusUnsupportedMediaType, reader.Err().Status)
+}
+
+func TestReader_Events_LineTooLong(t *testing.T) {
+	var mutex sync.Mutex
+	connections := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		connections++
+		mutex.Unlock()
+
+		writer.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(writer, "id: 1\ndata: ok\n\n")
+		fmt.Fprintf(writer, "data: %s\n\n", strings.Repeat("x", 128))
+	}))
+	defer server.Close()
+
+	reader := NewReader(newTestClient(), server.URL, Configs{RetryDelay: time.Millisecond, MaxLineSize: 64})
+	var received []Event
+	for event := range reader.Events(context.Background()) {
+		received = append(received, event)
+	}
+
+	assert.Len(t, received, 1)
+	assert.Equal(t, http.StatusBadGateway, reader.Err().Status)
+	assert.Equal(t, "event stream line too long", reader.Err().Message)
+	assert.ErrorIs(t, reader.Err(), bufio.ErrTooLong)
+	mutex.Lock()
+	assert.Equal(t, 1, connections)
+	mutex.Unlock()
+}
+
+func TestReader_Events_ReadErrorsCountAsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		// Promise more than is sent, so reading the body fails.
+		writer.Header().Set("Content-Type", "text/event-stream")
+		writer.Header().Set("Content-Length", "100")
+		fmt.Fprint(writer, "data: partial")
+	}))
+	defer server.Close()
+
+	reader := NewReader(newTestClient(), server.URL, Configs{RetryDelay: time.Millisecond, MaxRetries: 2})
+	_, open := <-reader.Events(context.Background())
+
+	assert.False(t, open)
+	assert.Equal(t, "error reading event stream", reader.Err().Message)
+}
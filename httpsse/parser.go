@@ -0,0 +1,162 @@
+package httpsse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event ...
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// parser implements the event stream interpretation of the HTML Living
+// Standard, section 9.2.6.
+type parser struct {
+	scanner *bufio.Scanner
+
+	lastEventID string
+	retry       time.Duration
+	eventType   string
+	data        strings.Builder
+	first       bool
+}
+
+func newParser(reader io.Reader, lastEventID string, retry time.Duration, maxLineSize int) *parser {
+	initialSize := 4096
+	if maxLineSize < initialSize {
+		initialSize = maxLineSize
+	}
+	scanner := bufio.NewScanner(reader)
+	// The scanner only enforces maxLineSize once the buffer has to grow.
+	scanner.Buffer(make([]byte, 0, initialSize), maxLineSize)
+	scanner.Split(scanLines)
+
+	return &parser{
+		scanner:     scanner,
+		lastEventID: lastEventID,
+		retry:       retry,
+		first:       true,
+	}
+}
+
+// next returns the next dispatched event. It returns false at the end of the
+// stream; an event that was not terminated by a blank line is discarded.
+func (p *parser) next() (Event, bool) {
+	for p.scanner.Scan() {
+		line := p.scanner.Text()
+		if p.first {
+			line = strings.TrimPrefix(line, "\uFEFF")
+			p.first = false
+		}
+
+		if line == "" {
+			if event, ok := p.dispatch(); ok {
+				return event, true
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, found := strings.Cut(line, ":")
+		if found {
+			value = strings.TrimPrefix(value, " ")
+		}
+		p.process(field, value)
+	}
+
+	return Event{}, false
+}
+
+func (p *parser) err() error {
+	return p.scanner.Err()
+}
+
+func (p *parser) process(field, value string) {
+	switch field {
+	case "event":
+		p.eventType = value
+	case "data":
+		p.data.WriteString(value)
+		p.data.WriteByte('\n')
+	case "id":
+		if !strings.ContainsRune(value, 0) {
+			p.lastEventID = value
+		}
+	case "retry":
+		if milliseconds, err := strconv.ParseUint(value, 10, 63); err == nil && isDigits(value) {
+			p.retry = time.Duration(milliseconds) * time.Millisecond
+		}
+	}
+}
+
+func (p *parser) dispatch() (Event, bool) {
+	defer func() {
+		p.eventType = ""
+		p.data.Reset()
+	}()
+
+	if p.data.Len() == 0 {
+		return Event{}, false
+	}
+
+	eventType := p.eventType
+	if eventType == "" {
+		eventType = "message"
+	}
+
+	return Event{
+		ID:    p.lastEventID,
+		Event: eventType,
+		Data:  strings.TrimSuffix(p.data.String(), "\n"),
+		Retry: p.retry,
+	}, true
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// scanLines splits on CRLF, LF or CR.
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if index := bytes.IndexAny(data, "\r\n"); index >= 0 {
+		if data[index] == '\n' {
+			return index + 1, data[:index], nil
+		}
+		if index+1 < len(data) {
+			if data[index+1] == '\n' {
+				return index + 2, data[:index], nil
+			}
+			return index + 1, data[:index], nil
+		}
+		if atEOF {
+			return index + 1, data[:index], nil
+		}
+		// A trailing CR may be the first half of a CRLF.
+		return 0, nil, nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,57 @@
+package httpsse
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseAll(content string) []Event {
+	parser := newParser(strings.NewReader(content), "", 0, defaultMaxLineSize)
+	var events []Event
+	for {
+		event, ok := parser.next()
+		if !ok {
+			return events
+		}
+		events = append(events, event)
+	}
+}
+
+func TestParser_Fields(t *testing.T) {
+	events := parseAll("\uFEFF: comment\nevent: update\ndata: first\ndata:second\nid: 7\nretry: 1500\n\ndata\n\n")
+
+	assert.Equal(t, []Event{
+		{ID: "7", Event: "update", Data: "first\nsecond", Retry: 1500 * time.Millisecond},
+		{ID: "7", Event: "message", Data: "", Retry: 1500 * time.Millisecond},
+	}, events)
+}
+
+func TestParser_LineEndings(t *testing.T) {
+	events := parseAll("data: crlf\r\n\r\ndata: cr\r\rdata: lf\n\n")
+
+	assert.Len(t, events, 3)
+	assert.Equal(t, "crlf", events[0].Data)
+	assert.Equal(t, "cr", events[1].Data)
+	assert.Equal(t, "lf", events[2].Data)
+}
+
+func TestParser_IgnoresInvalidFields(t *testing.T) {
+	events := parseAll("retry: soon\nid: a\x00b\nunknown: x\ndata: ok\n\n")
+
+	assert.Equal(t, []Event{{Event: "message", Data: "ok"}}, events)
+}
+
+func TestParser_DiscardsIncompleteEvent(t *testing.T) {
+	events := parseAll("data: done\n\ndata: partial")
+
+	assert.Equal(t, []Event{{Event: "message", Data: "done"}}, events)
+}
+
+func TestParser_EmptyEventResetsType(t *testing.T) {
+	events := parseAll("event: ignored\n\ndata: x\n\n")
+
+	assert.Equal(t, "message", events[0].Event)
+}
@@ -0,0 +1,248 @@
+// Package httptiming breaks the latency of a request down into DNS lookup,
+// TCP connect, TLS handshake and server time with net/http/httptrace.
+package httptiming
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timing is the breakdown of a request. The phases describe the last
+// attempt, or the one that won among concurrent attempts scoped with
+// WithAttempt, e.g. hedged ones. Total spans all of them. Callers sharing a
+// coalesced request get the phases of the shared request.
+type Timing struct {
+	DNSLookup    time.Duration `json:"dns_lookup"`
+	Connect      time.Duration `json:"connect"`
+	TLSHandshake time.Duration `json:"tls_handshake"`
+	// ServerProcessing runs from writing the request to the first response
+	// byte.
+	ServerProcessing time.Duration `json:"server_processing"`
+	// TimeToFirstByte runs from asking for a connection to the first
+	// response byte.
+	TimeToFirstByte time.Duration `json:"time_to_first_byte"`
+	Total           time.Duration `json:"total"`
+	Attempts        int           `json:"attempts"`
+	ConnReused      bool          `json:"conn_reused"`
+	// ConnIdle is how long a reused connection sat in the pool.
+	ConnIdle time.Duration `json:"conn_idle,omitempty"`
+}
+
+// String ...
+func (timing Timing) String() string {
+	parts := []string{}
+	if timing.ConnReused {
+		parts = append(parts, fmt.Sprintf("reused conn idle %s", timing.ConnIdle))
+	} else {
+		parts = append(parts,
+			fmt.Sprintf("dns %s", timing.DNSLookup),
+			fmt.Sprintf("connect %s", timing.Connect),
+			fmt.Sprintf("tls %s", timing.TLSHandshake),
+		)
+	}
+	parts = append(parts,
+		fmt.Sprintf("server %s", timing.ServerProcessing),
+		fmt.Sprintf("ttfb %s", timing.TimeToFirstByte),
+		fmt.Sprintf("total %s", timing.Total),
+	)
+	if timing.Attempts > 1 {
+		parts = append(parts, fmt.Sprintf("%d attempts", timing.Attempts))
+	}
+	return strings.Join(parts, ", ")
+}
+
+type recorderKey struct{}
+
+type attemptKey struct{}
+
+// Recorder collects the timing of the requests sent with its context.
+type Recorder struct {
+	mutex        sync.Mutex
+	timing       Timing
+	start        time.Time
+	attemptStart time.Time
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+	finished     bool
+
+	// parent is the recorder an attempt was scoped from. A recorder with
+	// attempts only counts them and leaves the phases to Commit.
+	parent *Recorder
+	scoped bool
+}
+
+// WithTrace returns a context that records the timing of requests sent with
+// it. Client traces already in ctx keep working.
+func WithTrace(ctx context.Context) (context.Context, *Recorder) {
+	recorder := &Recorder{start: time.Now()}
+	ctx = context.WithValue(ctx, recorderKey{}, recorder)
+	ctx = context.WithValue(ctx, attemptKey{}, recorder)
+	return httptrace.WithClientTrace(ctx, recorder.clientTrace()), recorder
+}
+
+// WithAttempt returns a context that records one of several concurrent
+// attempts of a request on its own recorder. The recorder of ctx then only
+// counts attempts, until Commit hands it the phases of the attempt that won.
+// FromContext keeps returning the recorder of ctx.
+func WithAttempt(ctx context.Context) (context.Context, *Recorder) {
+	parent, _ := ctx.Value(attemptKey{}).(*Recorder)
+	if parent != nil {
+		parent.mutex.Lock()
+		parent.scoped = true
+		parent.mutex.Unlock()
+	}
+
+	attempt := &Recorder{start: time.Now(), parent: parent}
+	ctx = context.WithValue(ctx, attemptKey{}, attempt)
+	return httptrace.WithClientTrace(ctx, attempt.clientTrace()), attempt
+}
+
+// Commit copies the phases of an attempt to the recorder it was scoped from.
+// It does nothing on a nil recorder.
+func (recorder *Recorder) Commit() {
+	if recorder != nil && recorder.parent != nil {
+		recorder.parent.adopt(recorder.Timing(), false)
+	}
+}
+
+// Adopt takes the phases and attempts of timing, e.g. of a request shared
+// with other callers. Total keeps measuring the request of the recorder. It
+// does nothing on a nil recorder.
+func (recorder *Recorder) Adopt(timing Timing) {
+	if recorder != nil {
+		recorder.adopt(timing, true)
+	}
+}
+
+func (recorder *Recorder) adopt(timing Timing, attempts bool) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	if !attempts {
+		timing.Attempts = recorder.timing.Attempts
+	}
+	timing.Total = recorder.timing.Total
+	recorder.timing = timing
+}
+
+func (recorder *Recorder) countAttempt() {
+	recorder.mutex.Lock()
+	recorder.timing.Attempts++
+	recorder.mutex.Unlock()
+
+	if recorder.parent != nil {
+		recorder.parent.countAttempt()
+	}
+}
+
+// FromContext returns the recorder added by WithTrace, or nil.
+func FromContext(ctx context.Context) *Recorder {
+	recorder, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return recorder
+}
+
+// FromResponse returns the timing of the request that produced response.
+func FromResponse(response *http.Response) (Timing, bool) {
+	if response == nil || response.Request == nil {
+		return Timing{}, false
+	}
+	recorder := FromContext(response.Request.Context())
+	if recorder == nil {
+		return Timing{}, false
+	}
+	return recorder.Timing(), true
+}
+
+// Finish stops the clock of Total. Until then Total keeps growing.
+func (recorder *Recorder) Finish() Timing {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	if !recorder.finished {
+		recorder.finished = true
+		recorder.timing.Total = time.Since(recorder.start)
+	}
+	return recorder.timing
+}
+
+// Timing ...
+func (recorder *Recorder) Timing() Timing {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	timing := recorder.timing
+	if !recorder.finished {
+		timing.Total = time.Since(recorder.start)
+	}
+	return timing
+}
+
+func (recorder *Recorder) record(update func(now time.Time)) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	if recorder.scoped {
+		return
+	}
+	update(time.Now())
+}
+
+func (recorder *Recorder) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			recorder.record(func(now time.Time) {
+				if recorder.parent != nil {
+					recorder.parent.countAttempt()
+				}
+				attempts := recorder.timing.Attempts + 1
+				recorder.timing = Timing{Attempts: attempts}
+				recorder.attemptStart = now
+				recorder.wroteRequest = time.Time{}
+			})
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			recorder.record(func(time.Time) {
+				recorder.timing.ConnReused = info.Reused
+				if info.WasIdle {
+					recorder.timing.ConnIdle = info.IdleTime
+				}
+			})
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			recorder.record(func(now time.Time) { recorder.dnsStart = now })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			recorder.record(func(now time.Time) { recorder.timing.DNSLookup = now.Sub(recorder.dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			recorder.record(func(now time.Time) { recorder.connectStart = now })
+		},
+		ConnectDone: func(string, string, error) {
+			recorder.record(func(now time.Time) { recorder.timing.Connect = now.Sub(recorder.connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			recorder.record(func(now time.Time) { recorder.tlsStart = now })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			recorder.record(func(now time.Time) { recorder.timing.TLSHandshake = now.Sub(recorder.tlsStart) })
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			recorder.record(func(now time.Time) { recorder.wroteRequest = now })
+		},
+		GotFirstResponseByte: func() {
+			recorder.record(func(now time.Time) {
+				recorder.timing.TimeToFirstByte = now.Sub(recorder.attemptStart)
+				if !recorder.wroteRequest.IsZero() {
+					recorder.timing.ServerProcessing = now.Sub(recorder.wroteRequest)
+				}
+			})
+		},
+	}
+}
@@ -0,0 +1,151 @@
+package httptiming
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, client *http.Client, ctx context.Context, url string) *http.Response {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	assert.NoError(t, err)
+	response, err := client.Do(request)
+	assert.NoError(t, err)
+	_, _ = io.Copy(io.Discard, response.Body)
+	assert.NoError(t, response.Body.Close())
+	return response
+}
+
+func TestWithTrace(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	ctx, recorder := WithTrace(context.Background())
+	response := get(t, server.Client(), ctx, server.URL)
+	timing := recorder.Finish()
+
+	assert.Equal(t, 1, timing.Attempts)
+	assert.False(t, timing.ConnReused)
+	assert.Greater(t, timing.Connect, time.Duration(0))
+	assert.Greater(t, timing.TLSHandshake, time.Duration(0))
+	assert.GreaterOrEqual(t, timing.ServerProcessing, 10*time.Millisecond)
+	assert.GreaterOrEqual(t, timing.TimeToFirstByte, timing.ServerProcessing)
+	assert.GreaterOrEqual(t, timing.Total, timing.TimeToFirstByte)
+
+	fromResponse, ok := FromResponse(response)
+	assert.True(t, ok)
+	assert.Equal(t, timing, fromResponse)
+}
+
+func TestWithTrace_ReusedConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+	defer server.Close()
+	client := server.Client()
+
+	get(t, client, context.Background(), server.URL)
+	time.Sleep(5 * time.Millisecond)
+	ctx, recorder := WithTrace(context.Background())
+	get(t, client, ctx, server.URL)
+	timing := recorder.Finish()
+
+	assert.True(t, timing.ConnReused)
+	assert.GreaterOrEqual(t, timing.ConnIdle, 5*time.Millisecond)
+	assert.Zero(t, timing.Connect)
+	assert.Contains(t, timing.String(), "reused conn idle ")
+}
+
+func TestWithTrace_KeepsExistingTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+	defer server.Close()
+
+	gotConn := false
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) { gotConn = true },
+	})
+	ctx, recorder := WithTrace(ctx)
+	get(t, server.Client(), ctx, server.URL)
+
+	assert.True(t, gotConn)
+	assert.Equal(t, 1, recorder.Finish().Attempts)
+	assert.Same(t, recorder, FromContext(ctx))
+}
+
+func TestWithAttempt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	ctx, recorder := WithTrace(context.Background())
+	slowCtx, _ := WithAttempt(ctx)
+	fastCtx, fast := WithAttempt(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		get(t, server.Client(), slowCtx, server.URL+"/slow")
+	}()
+	get(t, server.Client(), fastCtx, server.URL+"/fast")
+	fast.Commit()
+	<-done
+	timing := recorder.Finish()
+
+	assert.Same(t, recorder, FromContext(fastCtx))
+	assert.Equal(t, 2, timing.Attempts)
+	assert.Equal(t, fast.Timing().ServerProcessing, timing.ServerProcessing)
+	assert.Less(t, timing.ServerProcessing, 50*time.Millisecond)
+	assert.GreaterOrEqual(t, timing.Total, 50*time.Millisecond)
+}
+
+func TestRecorder_Adopt(t *testing.T) {
+	_, recorder := WithTrace(context.Background())
+	recorder.Adopt(Timing{ServerProcessing: time.Second, Total: time.Hour, Attempts: 1})
+	timing := recorder.Finish()
+
+	assert.Equal(t, time.Second, timing.ServerProcessing)
+	assert.Equal(t, 1, timing.Attempts)
+	assert.Less(t, timing.Total, time.Hour)
+
+	var missing *Recorder
+	missing.Adopt(timing)
+	missing.Commit()
+}
+
+func TestRecorder_Finish(t *testing.T) {
+	_, recorder := WithTrace(context.Background())
+	finished := recorder.Finish()
+	time.Sleep(2 * time.Millisecond)
+
+	assert.Equal(t, finished, recorder.Finish())
+	assert.Equal(t, finished, recorder.Timing())
+}
+
+func TestFromResponse_WithoutTrace(t *testing.T) {
+	_, ok := FromResponse(&http.Response{Request: httptest.NewRequest(http.MethodGet, "/", nil)})
+	assert.False(t, ok)
+
+	_, ok = FromResponse(nil)
+	assert.False(t, ok)
+}
+
+func TestTiming_String(t *testing.T) {
+	timing := Timing{
+		DNSLookup:        time.Millisecond,
+		Connect:          2 * time.Millisecond,
+		ServerProcessing: 5 * time.Millisecond,
+		TimeToFirstByte:  8 * time.Millisecond,
+		Total:            9 * time.Millisecond,
+		Attempts:         2,
+	}
+
+	assert.Equal(t, "dns 1ms, connect 2ms, tls 0s, server 5ms, ttfb 8ms, total 9ms, 2 attempts", timing.String())
+}